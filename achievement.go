@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pankona/gj/sim"
+)
+
+// achievement はプレイ中に条件を満たすと解除される実績
+type achievement struct {
+	name string
+	// 解除の条件
+	cond func(s *gameStats) bool
+}
+
+var achievements = []achievement{
+	{name: "First Blood", cond: func(s *gameStats) bool { return s.bugsKilled >= 1 }},
+	{name: "Exterminator", cond: func(s *gameStats) bool { return s.bugsKilled >= 100 }},
+	{name: "Architect", cond: func(s *gameStats) bool { return s.buildingsPlaced >= 10 }},
+	{name: "Slapper", cond: func(s *gameStats) bool { return s.slaps >= 50 }},
+	{name: "Untouched", cond: func(s *gameStats) bool { return s.wavesCleared >= 1 && s.houseDamage == 0 }},
+}
+
+// achievementTracker は統計を見て実績の解除を判定する
+// stats より後に購読すること (stats が数えたあとに判定するため)
+type achievementTracker struct {
+	game     *Game
+	stats    *gameStats
+	unlocked map[string]bool
+}
+
+func newAchievementTracker(g *Game, stats *gameStats) *achievementTracker {
+	return &achievementTracker{
+		game:     g,
+		stats:    stats,
+		unlocked: map[string]bool{},
+	}
+}
+
+func (t *achievementTracker) subscribe(bus *sim.EventBus) {
+	bus.Subscribe(func(e sim.Event) {
+		t.check()
+	})
+}
+
+func (t *achievementTracker) check() {
+	for _, a := range achievements {
+		if t.unlocked[a.name] || !a.cond(t.stats) {
+			continue
+		}
+		t.unlocked[a.name] = true
+
+		// 解除されたことを画面上部に出す
+		txt := newTimerText(t.game, 20, 20, fmt.Sprintf("Achievement unlocked: %s", a.name))
+		t.game.drawHandler.Add(txt)
+		t.game.updateHandler.Add(txt)
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"log"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/pankona/gj/sim"
+)
+
+// アニメーションの仕組み (docs/README.md の「アニメーションの仕組み」を参照)
+// コマの画像は初期化時にまとめて作っておき、アニメーションさせたいときには作らない
+
+type animationMode int
+
+const (
+	// 最後のコマまで行ったら最初のコマに戻る
+	animationLoop animationMode = iota
+	// 最後のコマまで行ったらそこで止まり、完了時のコールバックを呼ぶ
+	animationOnce
+)
+
+// animationClip はアニメーションのコマの並び
+// 同じ種類のものは同じ clip を共有する
+type animationClip struct {
+	frames []*ebiten.Image
+	// 1 コマを表示するフレーム数
+	frameDuration int
+	mode          animationMode
+}
+
+// totalFrame はコマの最初から最後までを表示し終えるのにかかるフレーム数
+func (c *animationClip) totalFrame() int {
+	return len(c.frames) * c.frameDuration
+}
+
+// Animation は clip を再生する
+// Update を呼ぶたびに 1 フレーム進む
+type Animation struct {
+	clip *animationClip
+
+	erapsedFrame int
+	finished     bool
+
+	// animationOnce のときに最後のコマを表示し終えたら呼ばれる
+	onComplete func()
+}
+
+func newAnimation(clip *animationClip, onComplete func()) *Animation {
+	return &Animation{
+		clip:       clip,
+		onComplete: onComplete,
+	}
+}
+
+func (a *Animation) Update() {
+	if a.finished {
+		return
+	}
+
+	a.erapsedFrame++
+
+	if a.clip.mode == animationOnce && a.erapsedFrame >= a.clip.totalFrame() {
+		a.finished = true
+		if a.onComplete != nil {
+			a.onComplete()
+		}
+	}
+}
+
+// frameIndex はいま表示するコマの番号を返す
+func (a *Animation) frameIndex() int {
+	i := a.erapsedFrame / a.clip.frameDuration
+	if a.clip.mode == animationLoop {
+		return i % len(a.clip.frames)
+	}
+	// 一度きりのアニメーションは最後のコマで止まる
+	return min(i, len(a.clip.frames)-1)
+}
+
+// Frame はいま表示するコマの画像を返す
+func (a *Animation) Frame() *ebiten.Image {
+	return a.clip.frames[a.frameIndex()]
+}
+
+func (a *Animation) Finished() bool {
+	return a.finished
+}
+
+// 虫のアニメーション
+type bugClips struct {
+	// 歩いている間はずっと繰り返す
+	walk *animationClip
+	// 攻撃したときに一度だけ再生する
+	attack *animationClip
+	// 死亡時にぺちゃんこになる
+	death *animationClip
+}
+
+var (
+	loadAnimationsOnce sync.Once
+
+	bugAnimationClips     map[sim.BugKind]*bugClips
+	buildingCollapseClips map[sim.BuildingKind]*animationClip
+)
+
+// loadAnimations はアニメーションのコマをまとめて作っておく
+// 何度呼んでも作るのは最初の一度だけ
+func loadAnimations() {
+	loadAnimationsOnce.Do(func() {
+		bugsImage := decodeImage(bugsImageData)
+		bugAnimationClips = map[sim.BugKind]*bugClips{}
+		for kind := range bugAppearances {
+			bugAnimationClips[kind] = newBugClips(bugImage(bugsImage, kind))
+		}
+
+		// 建物は下を軸に崩れる。家だけは真ん中を軸にぺちゃんこになる
+		buildingCollapseClips = map[sim.BuildingKind]*animationClip{
+			sim.BuildingHouse:      newCollapseClip(decodeImage(houseImageData), false),
+			sim.BuildingBarricade:  newCollapseClip(decodeImage(barricadeImageData), true),
+			sim.BuildingTower:      newCollapseClip(decodeImage(towerImageData), true),
+			sim.BuildingRadioTower: newCollapseClip(decodeImage(radioTowerImageData), true),
+		}
+	})
+}
+
+func decodeImage(data []byte) *ebiten.Image {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		log.Fatal(err)
+	}
+	return ebiten.NewImageFromImage(img)
+}
+
+func newBugClips(img *ebiten.Image) *bugClips {
+	return &bugClips{
+		// 左右反転したものと交互に出して、足を動かしているように見せる
+		walk: &animationClip{
+			frames:        []*ebiten.Image{img, flippedFrame(img)},
+			frameDuration: 8,
+			mode:          animationLoop,
+		},
+		// 一瞬大きくなって噛みつく
+		attack: &animationClip{
+			frames:        []*ebiten.Image{scaledFrame(img, 1.2), img},
+			frameDuration: 4,
+			mode:          animationOnce,
+		},
+		// 死亡時のアニメーションの長さはシミュレーションにあわせる
+		death: newSquashClip(img, sim.DeadAnimationTotalFrame, false),
+	}
+}
+
+func newCollapseClip(img *ebiten.Image, anchorBottom bool) *animationClip {
+	return newSquashClip(img, sim.DeadAnimationTotalFrame, anchorBottom)
+}
+
+// newSquashClip は img がだんだん縦につぶれていく n コマの clip を作る
+// anchorBottom が true なら下端を、false なら真ん中を軸につぶれる
+func newSquashClip(img *ebiten.Image, n int, anchorBottom bool) *animationClip {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	var frames []*ebiten.Image
+	for i := 0; i < n; i++ {
+		scale := 1.0 - float64(i)/float64(n)
+
+		axis := float64(h) / 2
+		if anchorBottom {
+			axis = float64(h)
+		}
+
+		frame := ebiten.NewImage(w, h)
+		opts := &ebiten.DrawImageOptions{}
+		opts.GeoM.Translate(0, -axis)
+		opts.GeoM.Scale(1, scale)
+		opts.GeoM.Translate(0, axis)
+		frame.DrawImage(img, opts)
+		frames = append(frames, frame)
+	}
+
+	return &animationClip{
+		frames:        frames,
+		frameDuration: 1,
+		mode:          animationOnce,
+	}
+}
+
+// flippedFrame は img を左右反転した画像を作る
+func flippedFrame(img *ebiten.Image) *ebiten.Image {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	frame := ebiten.NewImage(w, h)
+	opts := &ebiten.DrawImageOptions{}
+	opts.GeoM.Scale(-1, 1)
+	opts.GeoM.Translate(float64(w), 0)
+	frame.DrawImage(img, opts)
+	return frame
+}
+
+// scaledFrame は img を scale 倍にした画像を作る
+func scaledFrame(img *ebiten.Image, scale float64) *ebiten.Image {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	frame := ebiten.NewImage(int(float64(w)*scale), int(float64(h)*scale))
+	opts := &ebiten.DrawImageOptions{}
+	opts.GeoM.Scale(scale, scale)
+	frame.DrawImage(img, opts)
+	return frame
+}
+
+// drawFrameAt は frame を (x, y) が中心になるように scale 倍で描画する
+// コマごとに大きさが違っても中心がずれない
+func drawFrameAt(screen, frame *ebiten.Image, x, y int, scale float64, opts *ebiten.DrawImageOptions) {
+	w, h := frame.Bounds().Dx(), frame.Bounds().Dy()
+	opts.GeoM.Scale(scale, scale)
+	opts.GeoM.Translate(float64(x)-float64(w)*scale/2, float64(y)-float64(h)*scale/2)
+	screen.DrawImage(frame, opts)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestAnimationLoop(t *testing.T) {
+	clip := &animationClip{
+		frames:        make([]*ebiten.Image, 3),
+		frameDuration: 2,
+		mode:          animationLoop,
+	}
+	a := newAnimation(clip, nil)
+
+	want := []int{0, 0, 1, 1, 2, 2, 0, 0, 1}
+	for i, w := range want {
+		if got := a.frameIndex(); got != w {
+			t.Errorf("frame %d: expected index %d, got %d", i, w, got)
+		}
+		a.Update()
+	}
+	if a.Finished() {
+		t.Errorf("looping animation should never finish")
+	}
+}
+
+func TestAnimationOnce(t *testing.T) {
+	clip := &animationClip{
+		frames:        make([]*ebiten.Image, 2),
+		frameDuration: 3,
+		mode:          animationOnce,
+	}
+
+	var completed int
+	a := newAnimation(clip, func() { completed++ })
+
+	for i := 0; i < clip.totalFrame()-1; i++ {
+		a.Update()
+	}
+	if a.Finished() || completed != 0 {
+		t.Fatalf("animation should not finish before the last frame is shown")
+	}
+
+	a.Update()
+	if !a.Finished() || completed != 1 {
+		t.Fatalf("expected animation to finish and call the callback once")
+	}
+
+	// 終わったあとは最後のコマで止まり、コールバックも呼ばれない
+	for i := 0; i < 10; i++ {
+		a.Update()
+	}
+	if a.frameIndex() != 1 {
+		t.Errorf("expected animation to stay on the last frame, got %d", a.frameIndex())
+	}
+	if completed != 1 {
+		t.Errorf("expected the callback to be called only once, got %d", completed)
+	}
+}
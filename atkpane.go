@@ -27,23 +27,13 @@ var (
 	handBigImageData []byte
 )
 
+// シミュレーション上の手 (sim.Hand) を描画する
 type smallHand struct {
 	game *Game
 
-	x, y          int
 	width, height int
 	zindex        int
 
-	// 表示している時間
-	// これが 0 になったら消える
-	// この値は Update で減らす
-	displayTime int
-
-	cooldown    int
-	erapsedTime int // 攻撃実行からの経過時間
-
-	attackPower int
-
 	image *ebiten.Image
 }
 
@@ -68,10 +58,6 @@ func newSmallHand(game *Game) *smallHand {
 		height: img.Bounds().Dy(),
 		zindex: 100,
 		image:  ebiten.NewImageFromImage(img),
-
-		cooldown: 10, // ここを短くすると連打できるようになっていく
-
-		attackPower: 3,
 	}
 
 	smallHandPool = h
@@ -80,9 +66,14 @@ func newSmallHand(game *Game) *smallHand {
 }
 
 func (h *smallHand) Draw(screen *ebiten.Image) {
+	hand := h.game.world.Hand
+	if !hand.Visible() {
+		return
+	}
+
 	op := &ebiten.DrawImageOptions{}
 	// width と height を考慮する
-	op.GeoM.Translate(float64(h.x)-float64(h.width)/2, float64(h.y)-float64(h.height)/2)
+	op.GeoM.Translate(float64(hand.X)-float64(h.width)/2, float64(hand.Y)-float64(h.height)/2)
 	screen.DrawImage(h.image, op)
 }
 
@@ -90,44 +81,6 @@ func (h *smallHand) ZIndex() int {
 	return h.zindex
 }
 
-// smallHand implements updater interface
-func (h *smallHand) Update() {
-	h.erapsedTime++
-	h.displayTime--
-	if h.displayTime <= 0 {
-		h.game.updateHandler.Remove(h)
-		h.game.drawHandler.Remove(h)
-	}
-
-	// クリックから 5 フレーム後に攻撃を実行する
-	if h.erapsedTime == 5 {
-		getAudioPlayer().play(soundBinta)
-
-		// 攻撃範囲内にいる敵に対してダメージを与える
-		// ループの中で複数の h.game.enemies が減る可能性があるので、逆順でループする
-		for i := len(h.game.enemies) - 1; i >= 0; i-- {
-			e := h.game.enemies[i]
-
-			ex, ey := e.Position()
-			ew, eh := e.Size()
-
-			if intersects(
-				rect{h.x - h.width/2, h.y - h.height/2, h.width, h.height},
-				rect{ex - ew/2, ey - eh/2, ew, eh},
-			) {
-				var b Damager = e.(*bug)
-				b.Damage(h.attackPower)
-			}
-		}
-	}
-
-}
-
-func (h *smallHand) setPosition(x, y int) {
-	h.x = x
-	h.y = y
-}
-
 func newAttackPane(game *Game) *attackPane {
 	return &attackPane{
 		game: game,
@@ -143,22 +96,14 @@ func newAttackPane(game *Game) *attackPane {
 // attackPane implement Clickable interface
 // attackPane はクリックが下のオブジェクトに貫通する。攻撃中でも建物や敵の情報を見ることができるようにするため
 func (a *attackPane) OnClick(x, y int) bool {
-	hand := newSmallHand(a.game)
-
-	// cooldown があけていなかったら攻撃を発動しない
-	if hand.erapsedTime != 0 && hand.erapsedTime < hand.cooldown {
+	// 一時停止中は叩けない
+	if a.game.speedCtrl.paused {
 		return true
 	}
 
-	// smallHand をクリック位置に表示する
-	hand.setPosition(x, y)
-
-	// 見た目とクールダウンを一致させているが、かならずしもそうではないかも
-	hand.displayTime = hand.cooldown
-	hand.erapsedTime = 0
-
-	a.game.updateHandler.Add(hand)
-	a.game.drawHandler.Add(hand)
+	// クリック位置を叩く
+	// cooldown があけていなかったら攻撃は発動しない
+	a.game.world.Slap(x, y)
 
 	return true
 }
@@ -170,12 +115,3 @@ func (a *attackPane) IsClicked(x, y int) bool {
 func (a *attackPane) ZIndex() int {
 	return a.zindex
 }
-
-func (a *attackPane) RemoveAll() {
-	// 初期化順序の関係で a が nil になることがある
-	// TODO: みっともないので直せたら直す
-	if a == nil {
-		return
-	}
-	a.game.clickHandler.Remove(a)
-}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/audio/mp3"
+	"github.com/pankona/gj/sim"
 
 	_ "embed"
 )
@@ -51,7 +52,7 @@ var (
 
 const (
 	soundGyaa      = "gyaa"      // 虫が死んだときの音
-	soundBakuhatsu = "bakuhatsu" // 電波塔が範囲攻撃するときの音
+	soundBakuhatsu = "bakuhatsu" // 電波塔が範囲攻撃するとき、自爆虫が爆発するときの音
 	soundBeam      = "beam"      // 塔がビームを撃つときの音
 	soundBinta     = "binta"     // 手で叩くときの音
 	soundChoice    = "choice"    // ボタンを押下したときの音
@@ -153,3 +154,51 @@ func (a *audioPlayer) stopBGM() {
 	player := a.players[soundBgm]
 	player.Pause()
 }
+
+// subscribeSound はシミュレーションのイベントに応じて音を鳴らす
+func subscribeSound(bus *sim.EventBus) {
+	a := getAudioPlayer()
+
+	sim.Subscribe(bus, func(e sim.BugKilled) {
+		a.play(soundGyaa)
+	})
+	sim.Subscribe(bus, func(e sim.BugAttacked) {
+		// TODO: 攻撃時に音が鳴りすぎてパフォーマンス問題が発生するので、いったん音を鳴らさないようにしている
+		switch e.Bug.Kind {
+		case sim.BugRed:
+			//a.play(soundHikkaki)
+		case sim.BugBlue:
+			//a.play(soundHikkaki)
+		case sim.BugGreen:
+			//a.play(soundShot)
+		}
+	})
+	sim.Subscribe(bus, func(e sim.BugExploded) {
+		a.play(soundBakuhatsu)
+	})
+	sim.Subscribe(bus, func(e sim.BuildingPlaced) {
+		a.play(soundDon)
+	})
+	sim.Subscribe(bus, func(e sim.BuildingDestroyed) {
+		if e.Building.Kind != sim.BuildingHouse {
+			a.play(soundKuzureru)
+		}
+	})
+	sim.Subscribe(bus, func(e sim.TowerFired) {
+		a.play(soundBeam)
+	})
+	sim.Subscribe(bus, func(e sim.RadioTowerFired) {
+		a.play(soundBakuhatsu)
+	})
+	sim.Subscribe(bus, func(e sim.HandSlapped) {
+		a.play(soundBinta)
+	})
+	sim.Subscribe(bus, func(e sim.AllCleared) {
+		a.stopBGM()
+		a.play(soundClear)
+	})
+	sim.Subscribe(bus, func(e sim.GameOver) {
+		a.stopBGM()
+		a.play(soundGameover)
+	})
+}
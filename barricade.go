@@ -7,6 +7,7 @@ import (
 	"log"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/pankona/gj/sim"
 
 	_ "embed"
 	"image/color"
@@ -19,28 +20,27 @@ var barricadeImageData []byte
 type barricade struct {
 	game *Game
 
-	x, y          int
+	// シミュレーション上の建物
+	model *sim.Building
+
 	width, height int
 	zindex        int
 	image         *ebiten.Image
 
-	health int
-
 	// 画像の拡大率。
 	// 1以外を指定する場合は元画像のサイズをそもそも変更できないか検討すること
 	scale float64
 
-	// 壊れたときのアニメーションを制御するための変数
-	deadAnimationDuration int
-
-	// health が 0 になったときに呼ばれる関数
-	onDestroy func(b *barricade)
-
 	// この建物が他の建物と重なっているかどうか (建築確定前に用いるフラグ)
 	isOverlapping bool
+
+	// 壊れたときに崩れるアニメーション
+	collapse *Animation
 }
 
-func newBarricade(game *Game, x, y int, onDestroy func(b *barricade)) *barricade {
+func newBarricade(game *Game, x, y int) *barricade {
+	loadAnimations()
+
 	img, _, err := image.Decode(bytes.NewReader(barricadeImageData))
 	if err != nil {
 		log.Fatal(err)
@@ -49,51 +49,31 @@ func newBarricade(game *Game, x, y int, onDestroy func(b *barricade)) *barricade
 	h := &barricade{
 		game: game,
 
-		x:      x,
-		y:      y,
+		model: sim.NewBuilding(sim.BuildingBarricade, x, y),
+
 		width:  img.Bounds().Dx(),
 		height: img.Bounds().Dy(),
 		scale:  1,
 
-		health: 100,
-
 		image: ebiten.NewImageFromImage(img),
-
-		onDestroy: onDestroy,
 	}
 
 	return h
 }
 
-func (b *barricade) Update() {
-	if b.health <= 0 {
-		b.deadAnimationDuration++
-		if b.deadAnimationDuration >= deadAnimationTotalFrame {
-			b.onDestroy(b)
-		}
-		return
-	}
-}
-
 // 画面中央に配置
 func (b *barricade) Draw(screen *ebiten.Image) {
+	m := b.model
+
 	// 画像を描画
 	opts := &ebiten.DrawImageOptions{}
-	if b.health <= 0 {
+	img := b.image
+	if b.collapse != nil {
 		// 死亡時のアニメーションを行う
-		// ぺちゃんこになるように縮小する
-		scale := 1.0 - float64(b.deadAnimationDuration)/deadAnimationTotalFrame
-		if scale < 0 {
-			scale = 0
-		}
-
-		opts.GeoM.Translate(0, float64(-b.height))
-		opts.GeoM.Scale(1, scale)
-		opts.GeoM.Translate(0, float64(b.height))
-	} else {
-		opts.GeoM.Scale(b.scale, b.scale)
+		img = b.collapse.Frame()
 	}
-	opts.GeoM.Translate(float64(b.x)-float64(b.width)*b.scale/2, float64(b.y)-float64(b.height)*b.scale/2)
+	opts.GeoM.Scale(b.scale, b.scale)
+	opts.GeoM.Translate(float64(m.X)-float64(b.width)*b.scale/2, float64(m.Y)-float64(b.height)*b.scale/2)
 
 	// 他の建物と重なっている場合は赤くする
 	if b.isOverlapping {
@@ -103,40 +83,42 @@ func (b *barricade) Draw(screen *ebiten.Image) {
 		opts.ColorScale.Scale(0.5, 0.5, 0.5, 1)
 	}
 
-	screen.DrawImage(b.image, opts)
+	screen.DrawImage(img, opts)
+}
+
+// 壊れたら崩れるアニメーションを始める
+func (b *barricade) Update() {
+	if b.collapse == nil {
+		if b.model.Health > 0 {
+			return
+		}
+		b.collapse = newAnimation(buildingCollapseClips[b.model.Kind], nil)
+	}
+	b.collapse.Update()
 }
 
 func (b *barricade) ZIndex() int {
 	return b.zindex
 }
 
+func (b *barricade) Model() *sim.Building {
+	return b.model
+}
+
 func (b *barricade) Position() (int, int) {
-	return b.x, b.y
+	return b.model.Position()
 }
 
 func (b *barricade) SetPosition(x, y int) {
-	b.x = x
-	b.y = y
+	b.model.SetPosition(x, y)
 }
 
 func (b *barricade) Size() (int, int) {
-	return int(float64(b.width) * b.scale), int(float64(b.height) * b.scale)
+	return b.model.Size()
 }
 
 func (b *barricade) Name() string {
-	return "Barricade"
-}
-
-func (b *barricade) Damage(d int) {
-	if b.health <= 0 {
-		return
-	}
-
-	b.health -= d
-	if b.health <= 0 {
-		getAudioPlayer().play(soundKuzureru)
-		b.health = 0
-	}
+	return b.model.Name()
 }
 
 // barricade implements Clickable interface
@@ -168,12 +150,13 @@ func (b *barricade) OnClick(x, y int) bool {
 }
 
 func (b *barricade) Health() int {
-	return b.health
+	return b.model.Health
 }
 
 func (b *barricade) IsClicked(x, y int) bool {
+	bx, by := b.Position()
 	w, h := b.Size()
-	return b.x-w/2 <= x && x <= b.x+w/2 && b.y-h/2 <= y && y <= b.y+h/2
+	return bx-w/2 <= x && x <= bx+w/2 && by-h/2 <= y && y <= by+h/2
 }
 
 func (b *barricade) SetOverlap(overlap bool) {
@@ -182,25 +165,9 @@ func (b *barricade) SetOverlap(overlap bool) {
 
 func (b *barricade) IsOverlap() bool {
 	// 他の建物と重なっているかどうかを判定する
-	for _, building := range b.game.buildings {
-		if building == b {
-			continue
-		}
-
-		bx, by := building.Position()
-		bw, bh := building.Size()
-
-		if intersects(
-			rect{b.x - b.width/2, b.y - b.height/2, b.width, b.height},
-			rect{bx - bw/2, by - bh/2, bw, bh},
-		) {
-			return true
-		}
-	}
-
-	return false
+	return b.game.world.Overlaps(b.model)
 }
 
 func (b *barricade) Cost() int {
-	return CostBarricadeBuild
+	return b.model.Cost()
 }
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/pankona/gj/sim"
+)
+
+// subscribeBoss はボスが出てきたときに体力バーと登場のお知らせを出す
+func (g *Game) subscribeBoss(bus *sim.EventBus) {
+	sim.Subscribe(bus, func(e sim.BugSpawned) {
+		if e.Bug.Kind != sim.BugBoss {
+			return
+		}
+
+		bar := newBossHealthBar(g, e.Bug)
+		g.updateHandler.Add(bar)
+		g.drawHandler.Add(bar)
+
+		g.announce(fmt.Sprintf("%s APPEARED!", e.Bug.Name))
+	})
+	sim.Subscribe(bus, func(e sim.BossPhaseChanged) {
+		switch e.Phase {
+		case sim.BossPhaseSummon:
+			g.announce("THE BOSS CALLS ITS MINIONS!")
+		case sim.BossPhaseCharge:
+			g.announce("THE BOSS IS CHARGING!")
+		}
+	})
+}
+
+// announce は画面中央に text を点滅させてしばらく出す
+func (g *Game) announce(text string) {
+	a := newAnnouncement(g, text)
+	g.updateHandler.Add(a)
+	g.drawHandler.Add(a)
+}
+
+// bossHealthBar は画面上部に出すボスの体力バー
+// ボスが死んだら自分で消える
+type bossHealthBar struct {
+	game *Game
+
+	// シミュレーション上のボス
+	model *sim.Bug
+}
+
+func newBossHealthBar(g *Game, model *sim.Bug) *bossHealthBar {
+	return &bossHealthBar{
+		game:  g,
+		model: model,
+	}
+}
+
+func (b *bossHealthBar) Update() {
+	if b.model.IsDead() {
+		b.game.updateHandler.Remove(b)
+		b.game.drawHandler.Remove(b)
+	}
+}
+
+func (b *bossHealthBar) Draw(screen *ebiten.Image) {
+	const (
+		width  = 600
+		height = 20
+		y      = 110
+	)
+	x := screenWidth/2 - width/2
+
+	drawText(screen, b.model.Name, x, y-30, 2, 2, color.RGBA{0xff, 0xff, 0xff, 0xff})
+
+	// 枠と、残りの体力
+	vector.DrawFilledRect(screen, float32(x), y, width, height, color.RGBA{0x40, 0, 0, 0xc0}, true)
+	rest := float32(width) * float32(b.model.Health) / float32(b.model.MaxHealth)
+	vector.DrawFilledRect(screen, float32(x), y, rest, height, color.RGBA{0xe0, 0x20, 0x20, 0xff}, true)
+	vector.StrokeRect(screen, float32(x), y, width, height, 2, color.RGBA{0xff, 0xff, 0xff, 0xff}, true)
+}
+
+func (b *bossHealthBar) ZIndex() int {
+	return 300
+}
+
+// announcement は画面中央に点滅させて出すお知らせ
+type announcement struct {
+	game *Game
+	text string
+
+	displayFrame int
+}
+
+func newAnnouncement(g *Game, text string) *announcement {
+	return &announcement{
+		game:         g,
+		text:         text,
+		displayFrame: 180,
+	}
+}
+
+func (a *announcement) Update() {
+	a.displayFrame--
+	if a.displayFrame <= 0 {
+		a.game.updateHandler.Remove(a)
+		a.game.drawHandler.Remove(a)
+	}
+}
+
+func (a *announcement) Draw(screen *ebiten.Image) {
+	if a.displayFrame%40 < 10 {
+		return
+	}
+
+	// 文字の幅は 1 文字 6 ドットとして真ん中に寄せる
+	const scale = 4
+	x := screenWidth/2 - len(a.text)*6*scale/2
+	drawText(screen, a.text, x, eScreenHeight/2-200, scale, scale, color.RGBA{0xff, 0x40, 0x40, 0xff})
+}
+
+func (a *announcement) ZIndex() int {
+	return 310
+}
@@ -1,13 +1,12 @@
 package main
 
 import (
-	"bytes"
 	"image"
 	"log"
-	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/pankona/gj/sim"
 
 	_ "embed"
 	"image/color"
@@ -17,123 +16,85 @@ import (
 //go:embed assets/bugs.png
 var bugsImageData []byte
 
-type bugColor int
-
 type bug struct {
 	game *Game
 
-	x, y          int
-	width, height int
-	zindex        int
-	image         *ebiten.Image
-	selfColor     bugColor
-
-	name        string
-	health      int
-	speed       float64
-	attackPower int
-	attackRange float64
-
-	// 攻撃クールダウン
-	// 初期化時に設定するものではなく、攻撃後に設定するもの
-	attackCooldown int
-
-	// 攻撃中であるかどうかを示すフラグと攻撃アニメーションの時間
-	// 攻撃アニメーションを行うために用いる
-	attacking            bool
-	attackDuration       int
-	originalX, originalY int
+	// シミュレーション上の虫
+	model *sim.Bug
 
-	// 死亡時のアニメーションを管理するための変数
-	deadAnimationDuration int
-
-	// 画像の拡大率。
-	// TODO: 本当は画像のサイズそのものを変更したほうが見た目も処理効率も良くなる。余裕があれば後々やろう。
-	scale float64
+	zindex int
 
-	// health が 0 になったときに呼ばれる関数
-	onDestroy func(b *bug)
+	clips *bugClips
+	// いま再生しているアニメーション
+	anim *Animation
 }
 
-const (
-	bugsRed bugColor = iota
-	bugsBlue
-	bugsGreen
-)
+func newBug(game *Game, model *sim.Bug) *bug {
+	loadAnimations()
 
-const (
-	deadAnimationTotalFrame = 10
-)
-
-func newBug(game *Game, bugColor bugColor, x, y int, onDestroy func(b *bug)) *bug {
-	img, _, err := image.Decode(bytes.NewReader(bugsImageData))
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	bugsImage := ebiten.NewImageFromImage(img)
-	rect := func() image.Rectangle {
-		switch bugColor {
-		case bugsRed:
-			return redBug()
-		case bugsBlue:
-			return blueBug()
-		case bugsGreen:
-			return greenBug()
-		}
-		log.Fatal("invalid bug color")
-		return image.Rectangle{}
-	}()
-	bugImage := bugsImage.SubImage(rect).(*ebiten.Image)
-
-	bug := &bug{
+	b := &bug{
 		game: game,
 
-		x:         x,
-		y:         y,
-		width:     bugImage.Bounds().Dx(),
-		height:    bugImage.Bounds().Dy(),
-		zindex:    50,
-		image:     bugImage,
-		selfColor: bugColor,
+		model: model,
 
-		// TODO: 虫種別によって異なる値を設定できるようにする
-		name:   "Red bug",
-		health: 2,
+		zindex: 50,
+		clips:  bugAnimationClips[model.Kind],
+	}
+	b.anim = newAnimation(b.clips.walk, nil)
 
-		speed:       5,
-		attackPower: 1,
-		attackRange: 1,
+	return b
+}
 
-		scale: 1,
+// bugAppearance は虫の種類ごとの見た目
+type bugAppearance struct {
+	// クリックしたときに表示する名前
+	label string
+	// bugs.png の中の切り出し範囲
+	rect image.Rectangle
+	// 色違いにするときの色。nil のときは元の色のまま
+	tint color.Color
+}
+
+var bugAppearances = map[sim.BugKind]bugAppearance{
+	sim.BugRed:   {label: "red bug", rect: redBug()},
+	sim.BugBlue:  {label: "blue bug", rect: blueBug()},
+	sim.BugGreen: {label: "green bug", rect: greenBug()},
+	// 羽虫は青虫の色違い
+	sim.BugFly: {label: "flying bug", rect: blueBug(), tint: color.RGBA{R: 0xe0, G: 0xc0, B: 0xff, A: 0xff}},
+	// ボスは緑虫を赤黒くして大きく描く (大きさは sim.Bug.Scale)
+	sim.BugBoss: {label: "boss bug", rect: greenBug(), tint: color.RGBA{R: 0xc0, G: 0x50, B: 0x50, A: 0xff}},
+	// 女王虫は赤虫を金色にして大きく描く
+	sim.BugQueen: {label: "queen bug", rect: redBug(), tint: color.RGBA{R: 0xff, G: 0xd7, B: 0x40, A: 0xff}},
+	// 回復虫は赤虫を薄緑にする
+	sim.BugHealer: {label: "healer bug", rect: redBug(), tint: color.RGBA{R: 0x90, G: 0xff, B: 0xc0, A: 0xff}},
+	// 自爆虫は青虫を橙色にする
+	sim.BugKamikaze: {label: "kamikaze bug", rect: blueBug(), tint: color.RGBA{R: 0xff, G: 0x90, B: 0x30, A: 0xff}},
+	// 甲虫は緑虫を鋼色にする
+	sim.BugBeetle: {label: "beetle", rect: greenBug(), tint: color.RGBA{R: 0x80, G: 0x90, B: 0xb0, A: 0xff}},
+}
 
-		onDestroy: onDestroy,
+func bugAppearanceOf(kind sim.BugKind) bugAppearance {
+	a, ok := bugAppearances[kind]
+	if !ok {
+		log.Fatal("invalid bug kind")
 	}
+	return a
+}
 
-	switch bugColor {
-	case bugsRed:
-		bug.speed = 5
-		bug.attackPower = 1
-		bug.attackRange = 1
-		bug.health = 3
-		bug.name = "Red bug"
-	case bugsBlue:
-		bug.speed = 4
-		bug.attackPower = 1
-		bug.attackRange = 1
-		bug.health = 5
-		bug.name = "Blue bug"
-	case bugsGreen:
-		bug.speed = 3
-		bug.attackPower = 1
-		bug.attackRange = 50
-		bug.health = 7
-		bug.name = "Green bug"
-	default:
-		log.Fatal("invalid bug color")
+// bugImage は bugsImage の中から kind の虫の画像を切り出す
+// 色違いの虫は色をつけた画像にする
+func bugImage(bugsImage *ebiten.Image, kind sim.BugKind) *ebiten.Image {
+	a := bugAppearanceOf(kind)
+	img := bugsImage.SubImage(a.rect).(*ebiten.Image)
+	if a.tint == nil {
+		return img
 	}
 
-	return bug
+	tinted := ebiten.NewImage(a.rect.Dx(), a.rect.Dy())
+	opts := &ebiten.DrawImageOptions{}
+	opts.ColorScale.ScaleWithColor(a.tint)
+	tinted.DrawImage(img, opts)
+	return tinted
 }
 
 func redBug() image.Rectangle {
@@ -148,47 +109,6 @@ func greenBug() image.Rectangle {
 	return image.Rect(35, 50, 66, 96)
 }
 
-func (b *bug) Update() {
-	if b.health <= 0 {
-		b.deadAnimationDuration++
-		if b.deadAnimationDuration >= deadAnimationTotalFrame {
-			b.onDestroy(b)
-		}
-		return
-	}
-
-	switch b.selfColor {
-	case bugsRed:
-		redBugUpdate(b)
-	case bugsBlue:
-		blueBugUpdate(b)
-	case bugsGreen:
-		greenBugUpdate(b)
-	default:
-		log.Fatal("invalid bug color")
-	}
-}
-
-func (b *bug) attack(a Damager) {
-	a.Damage(b.attackPower)
-
-	// エフェクトや音を制御する
-	// TODO: 攻撃時に音が鳴りすぎてパフォーマンス問題が発生するので、いったん音を鳴らさないようにしている
-	switch b.selfColor {
-	case bugsRed:
-		//getAudioPlayer().play(soundHikkaki)
-	case bugsBlue:
-		//getAudioPlayer().play(soundHikkaki)
-	case bugsGreen:
-		//getAudioPlayer().play(soundShot)
-
-		tx, ty := a.(Building).Position()
-		e := newGreenBugAttackEffect(b.game, b.x, b.y, tx, ty)
-		b.game.updateHandler.Add(e)
-		b.game.drawHandler.Add(e)
-	}
-}
-
 type greenBugAttackEffect struct {
 	game *Game
 
@@ -239,390 +159,176 @@ func (e *greenBugAttackEffect) ZIndex() int {
 	return e.zindex
 }
 
-type Damager interface {
-	Damage(int)
-}
+// healEffect は回復虫から回復した虫に向かって光の粒が飛んでいくエフェクト
+// 回復した虫の位置は動くので、毎フレーム追いかける
+type healEffect struct {
+	game *Game
 
-func (b *bug) Damage(d int) {
-	if b.health <= 0 {
-		return
-	}
+	startX, startY int
+	target         *sim.Bug
 
-	b.health -= d
+	erapsedFrame int
 
-	if b.health <= 0 {
-		b.health = 0
-		getAudioPlayer().play(soundGyaa)
-	}
+	zindex int
 }
 
-type rect struct {
-	x, y, width, height int
-}
-
-func intersects(r1, r2 rect) bool {
-	return r1.x < r2.x+r2.width &&
-		r2.x < r1.x+r1.width &&
-		r1.y < r2.y+r2.height &&
-		r2.y < r1.y+r1.height
-}
-
-func redBugUpdate(b *bug) {
-	// target に向かう途中に障害物が攻撃射程に入ったとき、その障害物を target とする
-	// いずれかの建物が攻撃レンジに入っているか確認
-	var attackTarget Damager
-	for _, building := range b.game.buildings {
-		x, y := building.Position()
-		width, height := building.Size()
-
-		// 対象の建物と bugs の攻撃範囲を踏まえた当たり判定を行う
-		// bugs は size + attackRange の範囲を当たり判定として用いる
-		if intersects(
-			// bug
-			rect{
-				b.x - b.width/2 - int(b.attackRange), b.y - b.height/2 - int(b.attackRange),
-				b.width + int(b.attackRange)*2, b.height + int(b.attackRange)*2,
-			},
-			// building
-			rect{x - width/2, y - height/2,
-				width, height},
-		) {
-			// 攻撃射程圏内であるので、その建物を attack 対象にする
-			attackTarget = building.(Damager)
-
-			break
-		}
-	}
-
-	// attack target がいるならば攻撃する。そうでないならば house に向かう
-	if attackTarget != nil {
-		// クールダウン中でなければ攻撃
-		if b.attackCooldown <= 0 {
-			b.attack(attackTarget)
-			b.attackCooldown = 60
-
-			b.attacking = true
-			b.attackDuration = 7
-			b.originalX, b.originalY = b.x, b.y
-		} else {
-			// クールダウンを消化する
-			b.attackCooldown -= 1
-		}
-
-		// 攻撃中であればアニメーション動作を行う
-		if b.attacking {
-			b.attackDuration--
-			if b.attackDuration <= 0 {
-				b.x = b.originalX
-				b.y = b.originalY
-				b.attacking = false
-			} else {
-				// 攻撃対象に向かって一瞬スプライトを移動させる
-				targetX, targetY := attackTarget.(Building).Position()
-				dx := (targetX - b.x) / 4
-				dy := (targetY - b.y) / 4
-				b.x += dx / b.attackDuration
-				b.y += dy / b.attackDuration
-			}
-		}
-
-		return
-	}
+func newHealEffect(game *Game, startX, startY int, target *sim.Bug) *healEffect {
+	return &healEffect{
+		game: game,
 
-	// house に向かう
-	var moveTargetX, moveTargetY int
-	var found bool
+		startX: startX,
+		startY: startY,
+		target: target,
 
-	for _, building := range b.game.buildings {
-		if building.Name() == "House" {
-			moveTargetX, moveTargetY = building.Position()
-			found = true
-			break
-		}
+		zindex: 220,
 	}
+}
 
-	if !found {
-		// すべての建物が破壊されている場合はその場にとどまる
+func (e *healEffect) Update() {
+	// 20 frame かけて target に届き、そのあと 20 frame だけ十字を出す
+	if e.erapsedFrame < 40 {
+		e.erapsedFrame++
 		return
 	}
+	e.game.updateHandler.Remove(e)
+	e.game.drawHandler.Remove(e)
+}
 
-	// ターゲットへの直線距離を計算
-	dx := moveTargetX - b.x
-	dy := moveTargetY - b.y
-
-	// 移動方向のラジアンを計算
-	angle := math.Atan2(float64(dy), float64(dx))
-
-	// 回避動作
-	// 虫同士がぴったり重ならないようにするための計算
-	// やや自信のないロジックではある
-	avoidX, avoidY := 0.0, 0.0
-	for _, e := range b.game.enemies {
-		ee := e.(*bug)
-		if ee != b {
-			distX := float64(ee.x - b.x)
-			distY := float64(ee.y - b.y)
-			distance := math.Sqrt(distX*distX + distY*distY)
-			if distance > 0 && distance < float64(b.width) {
-				avoidX -= distX / distance
-				avoidY -= distY / distance
-			}
-		}
-	}
-
-	// 移動
-	moveX := math.Cos(angle)*b.speed + avoidX
-	moveY := math.Sin(angle)*b.speed + avoidY
-	b.x += int(moveX)
-	b.y += int(moveY)
-}
-
-func blueBugUpdate(b *bug) {
-	// 青虫の特徴
-	// 最寄りの障害物に向かって進む。障害物にぶつかったら、ぶつかったものに対して攻撃を行う。
-	// 攻撃は一定時間ごとに行う。攻撃機範囲はせまい。自身の周囲ちょっとくらい (赤虫と同じ)。
-	// 体力は赤虫よりもちょっと多い。
-	// 赤虫より多く出現する。
-	// 動きの速さは普通。
-
-	// 最寄りの障害物を探す
-	var nearestBuilding Damager
-	nearestDistance := math.MaxFloat64
-	for _, building := range b.game.buildings {
-		x, y := building.Position()
-		// 対象の建物と bug の距離を計算
-		dx := x - b.x
-		dy := y - b.y
-		distance := math.Sqrt(float64(dx*dx + dy*dy))
-		if distance < nearestDistance {
-			nearestDistance = distance
-			nearestBuilding = building.(Damager)
-		}
-	}
-
-	// 最寄りの建物が攻撃範囲内にあるか確認
+func (e *healEffect) Draw(screen *ebiten.Image) {
+	clr := color.RGBA{R: 0x40, G: 0xff, B: 0x80, A: 0xc0}
+	tx, ty := e.target.Position()
 
-	// 対象の建物と bugs の攻撃範囲を踏まえた当たり判定を行う
-	// bugs は size + attackRange の範囲を当たり判定として用いる
-	if nearestBuilding == nil {
-		// すべての建物が破壊されている場合はその場にとどまる
+	if e.erapsedFrame < 20 {
+		// 光の粒が target に向かって飛んでいく
+		x := e.startX + (tx-e.startX)*e.erapsedFrame/20
+		y := e.startY + (ty-e.startY)*e.erapsedFrame/20
+		vector.DrawFilledCircle(screen, float32(x), float32(y), 5, clr, true)
 		return
 	}
-	target := nearestBuilding.(Building)
-	x, y := target.Position()
-	width, height := target.Size()
-	var attackTarget Damager
-	if intersects(
-		// bug
-		rect{
-			b.x - b.width/2 - int(b.attackRange), b.y - b.height/2 - int(b.attackRange),
-			b.width + int(b.attackRange)*2, b.height + int(b.attackRange)*2,
-		},
-		// building
-		rect{x - width/2, y - height/2,
-			width, height},
-	) {
-		// 攻撃射程圏内であるので、その建物を attack 対象にする
-		attackTarget = nearestBuilding
-	}
 
-	if attackTarget != nil {
-		// クールダウン中でなければ攻撃
-		if b.attackCooldown <= 0 {
-			b.attack(nearestBuilding)
-			b.attackCooldown = 60
-
-			b.attacking = true
-			b.attackDuration = 7
-			b.originalX, b.originalY = b.x, b.y
-		} else {
-			// クールダウンを消化する
-			b.attackCooldown -= 1
-		}
+	// 届いたら target の上に十字を出す
+	y := float32(ty - e.target.Height/2 - 10)
+	vector.StrokeLine(screen, float32(tx-8), y, float32(tx+8), y, 4, clr, true)
+	vector.StrokeLine(screen, float32(tx), y-8, float32(tx), y+8, 4, clr, true)
+}
 
-		// 攻撃中であればアニメーション動作を行う
-		if b.attacking {
-			b.attackDuration--
-			if b.attackDuration <= 0 {
-				b.x = b.originalX
-				b.y = b.originalY
-				b.attacking = false
-			} else {
-				// 攻撃対象に向かって一瞬スプライトを移動させる
-				targetX, targetY := attackTarget.(Building).Position()
-				dx := (targetX - b.x) / 4
-				dy := (targetY - b.y) / 4
-				b.x += dx / b.attackDuration
-				b.y += dy / b.attackDuration
-			}
-		}
+func (e *healEffect) ZIndex() int {
+	return e.zindex
+}
 
-		// クールダウン中でかつ攻撃対象が攻撃範囲内にいるときにはその場にとどまる
-		return
-	}
+// blastEffect は自爆虫の爆発のエフェクト
+// 爆発の範囲いっぱいまで火の玉が広がりながら消えていく
+type blastEffect struct {
+	game *Game
 
-	// 最寄りの建物に向かって移動
-	moveTargetX, moveTargetY := nearestBuilding.(Building).Position()
-
-	// ターゲットへの直線距離を計算
-	dx := moveTargetX - b.x
-	dy := moveTargetY - b.y
-
-	// 移動方向のラジアンを計算
-	angle := math.Atan2(float64(dy), float64(dx))
-
-	// 回避動作
-	// 虫同士がぴったり重ならないようにするための計算
-	// やや自信のないロジックではある
-	avoidX, avoidY := 0.0, 0.0
-	for _, e := range b.game.enemies {
-		ee := e.(*bug)
-		if ee != b {
-			distX := float64(ee.x - b.x)
-			distY := float64(ee.y - b.y)
-			distance := math.Sqrt(distX*distX + distY*distY)
-			if distance > 0 && distance < float64(b.width) {
-				avoidX -= distX / distance
-				avoidY -= distY / distance
-			}
-		}
-	}
+	x, y   int
+	radius float64
 
-	// 移動
-	moveX := math.Cos(angle)*b.speed + avoidX
-	moveY := math.Sin(angle)*b.speed + avoidY
-	b.x += int(moveX)
-	b.y += int(moveY)
-}
-
-func greenBugUpdate(b *bug) {
-	// 緑虫の特徴
-	// 家に向かって一直線に進む。
-	// 攻撃は一定時間ごとに行う。攻撃範囲が広い。飛び道具のようなものを放つ。
-	// 攻撃範囲に任意の障害物が入ったとき、その障害物に向かって攻撃を行う。
-	// 体力は青虫よりも多い。
-	// 出現頻度は低い。
-	// 動きは遅い。
-
-	// target に向かう途中に障害物が攻撃射程に入ったとき、その障害物を target とする
-	// いずれかの建物が攻撃レンジに入っているか確認
-	var attackTarget Damager
-	for _, building := range b.game.buildings {
-		x, y := building.Position()
-		width, height := building.Size()
-
-		// 対象の建物と bugs の攻撃範囲を踏まえた当たり判定を行う
-		// bugs は size + attackRange の範囲を当たり判定として用いる
-		if intersects(
-			// bug
-			rect{
-				b.x - b.width/2 - int(b.attackRange), b.y - b.height/2 - int(b.attackRange),
-				b.width + int(b.attackRange)*2, b.height + int(b.attackRange)*2,
-			},
-			// building
-			rect{x - width/2, y - height/2,
-				width, height},
-		) {
-			// 攻撃射程圏内であるので、その建物を attack 対象にする
-			attackTarget = building.(Damager)
-
-			break
-		}
-	}
+	erapsedFrame int
 
-	// attack target がいるならば攻撃する。そうでないならば house に向かう
-	if attackTarget != nil {
-		// クールダウン中でなければ攻撃
-		if b.attackCooldown <= 0 {
-			b.attack(attackTarget)
-			b.attackCooldown = 60
-		} else {
-			// クールダウンを消化する
-			b.attackCooldown -= 1
-		}
+	zindex int
+}
 
-		return
-	}
+func newBlastEffect(game *Game, x, y int, radius float64) *blastEffect {
+	return &blastEffect{
+		game:   game,
+		x:      x,
+		y:      y,
+		radius: radius,
 
-	// house に向かう
-	var moveTargetX, moveTargetY int
-	var found bool
-	for _, building := range b.game.buildings {
-		if building.Name() == "House" {
-			moveTargetX, moveTargetY = building.Position()
-			found = true
-			break
-		}
+		zindex: 220,
 	}
+}
 
-	if !found {
-		// すべての建物が破壊されている場合はその場にとどまる
+func (e *blastEffect) Update() {
+	// 20 frame かけて広がる
+	if e.erapsedFrame < 20 {
+		e.erapsedFrame++
 		return
 	}
+	e.game.updateHandler.Remove(e)
+	e.game.drawHandler.Remove(e)
+}
 
-	// ターゲットへの直線距離を計算
-	dx := moveTargetX - b.x
-	dy := moveTargetY - b.y
-
-	// 移動方向のラジアンを計算
-	angle := math.Atan2(float64(dy), float64(dx))
-
-	// 回避動作
-	// 虫同士がぴったり重ならないようにするための計算
-	// やや自信のないロジックではある
-	avoidX, avoidY := 0.0, 0.0
-	for _, e := range b.game.enemies {
-		ee := e.(*bug)
-		if ee != b {
-			distX := float64(ee.x - b.x)
-			distY := float64(ee.y - b.y)
-			distance := math.Sqrt(distX*distX + distY*distY)
-			if distance > 0 && distance < float64(b.width) {
-				avoidX -= distX / distance
-				avoidY -= distY / distance
-			}
-		}
-	}
+func (e *blastEffect) Draw(screen *ebiten.Image) {
+	r := float32(e.radius) * float32(e.erapsedFrame+1) / 20
+	alpha := uint8(0xe0 * (20 - e.erapsedFrame) / 20)
+	vector.DrawFilledCircle(screen, float32(e.x), float32(e.y), r, color.RGBA{R: alpha, G: alpha / 2, A: alpha}, true)
+	vector.DrawFilledCircle(screen, float32(e.x), float32(e.y), r/2, color.RGBA{R: alpha, G: alpha, B: alpha / 2, A: alpha}, true)
+}
+
+func (e *blastEffect) ZIndex() int {
+	return e.zindex
+}
 
-	// 移動
-	moveX := math.Cos(angle)*b.speed + avoidX
-	moveY := math.Sin(angle)*b.speed + avoidY
-	b.x += int(moveX)
-	b.y += int(moveY)
+func (b *bug) Model() *sim.Bug {
+	return b.model
 }
 
 func (b *bug) Name() string {
-	return b.name
+	return b.model.Name
 }
 
 func (b *bug) Position() (int, int) {
-	return b.x, b.y
+	return b.model.Position()
 }
 
 func (b *bug) Size() (int, int) {
-	return b.width, b.height
+	return b.model.Size()
+}
+
+// シミュレーション上の虫の状態にあわせてアニメーションを切り替える
+func (b *bug) Update() {
+	m := b.model
+
+	switch {
+	case m.IsDead():
+		// 死亡時のアニメーションを行う
+		if b.anim.clip != b.clips.death {
+			b.anim = newAnimation(b.clips.death, nil)
+		}
+	case m.Attacking():
+		// 攻撃のアニメーションが終わったら歩くアニメーションに戻る
+		if b.anim.clip == b.clips.walk {
+			b.anim = newAnimation(b.clips.attack, func() {
+				b.anim = newAnimation(b.clips.walk, nil)
+			})
+		}
+	}
+
+	b.anim.Update()
 }
 
+// 浮いている虫を地面からどれだけ上に描くか
+const flyingHeight = 12
+
 // 画面中央に配置
 func (b *bug) Draw(screen *ebiten.Image) {
+	m := b.model
+
+	y := m.Y
+	if m.Flying && !m.IsDead() {
+		// 浮いている虫は足元に影を落として、少し上に描く
+		vector.DrawFilledCircle(screen, float32(m.X), float32(m.Y+m.Height/2), float32(m.Width)/3, color.RGBA{A: 0x60}, true)
+		y -= flyingHeight
+	}
+
 	// 画像を描画
 	opts := &ebiten.DrawImageOptions{}
-	if b.health <= 0 {
-		// 死亡時のアニメーションを行う
-		// ぺちゃんこになるように縮小する
-		scale := 1.0 - float64(b.deadAnimationDuration)/deadAnimationTotalFrame
-		if scale < 0 {
-			scale = 0
-		}
-
-		opts.GeoM.Translate(0, float64(-b.height)/2)
-		opts.GeoM.Scale(1, scale)
-		opts.GeoM.Translate(0, float64(b.height)/2)
-	} else {
-		opts.GeoM.Scale(b.scale, b.scale)
+	frame := b.anim.Frame()
+	if m.Flipped {
+		// 甲羅がひっくり返っている虫は上下を逆さまに描く
+		opts.GeoM.Scale(1, -1)
+		opts.GeoM.Translate(0, float64(frame.Bounds().Dy()))
+	}
+	// 状態異常にかかっている虫は色味を変えて、頭の上にアイコンを出す
+	if !m.IsDead() {
+		tintStatus(opts, m)
+	}
+	drawFrameAt(screen, frame, m.X, y, m.Scale, opts)
+	if !m.IsDead() {
+		drawStatusIcons(screen, m, y)
 	}
-	opts.GeoM.Translate(float64(b.x)-float64(b.width)*b.scale/2, float64(b.y)-float64(b.height)*b.scale/2)
-	screen.DrawImage(b.image, opts)
 }
 
 func (b *bug) ZIndex() int {
@@ -630,19 +336,10 @@ func (b *bug) ZIndex() int {
 }
 
 func (b *bug) OnClick(x, y int) bool {
-	switch b.selfColor {
-	case bugsRed:
-		b.game.clickedObject = "red bug"
-	case bugsBlue:
-		b.game.clickedObject = "blue bug"
-	case bugsGreen:
-		b.game.clickedObject = "green bug"
-	default:
-		log.Fatal("invalid bug color")
-	}
+	b.game.clickedObject = bugAppearanceOf(b.model.Kind).label
 
 	// infoPanel に情報を表示する
-	icon := newBugIcon(80, eScreenHeight+70, b.selfColor)
+	icon := newBugIcon(80, eScreenHeight+70, b.model.Kind)
 	b.game.infoPanel.setIcon(icon)
 	b.game.infoPanel.setUnit(b)
 
@@ -650,10 +347,11 @@ func (b *bug) OnClick(x, y int) bool {
 }
 
 func (b *bug) Health() int {
-	return b.health
+	return b.model.Health
 }
 
 func (b *bug) IsClicked(x, y int) bool {
-	width, height := b.width, b.height
-	return b.x-width/2 <= x && x <= b.x+width/2 && b.y-height/2 <= y && y <= b.y+height/2
+	bx, by := b.model.Position()
+	width, height := b.model.Size()
+	return bx-width/2 <= x && x <= bx+width/2 && by-height/2 <= y && y <= by+height/2
 }
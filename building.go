@@ -1,30 +1,44 @@
 package main
 
+import (
+	"log"
+
+	"github.com/pankona/gj/sim"
+)
+
 type Building interface {
 	Position() (int, int)
 	SetPosition(int, int)
 	Size() (int, int)
-	Name() string
 
 	SetOverlap(bool)
 	IsOverlap() bool
 
 	Cost() int
 
-	Clickable
-	Drawable
-	Updater
-}
+	// シミュレーション上の建物
+	Model() *sim.Building
 
-func (g *Game) AddBuilding(b Building) {
-	g.buildings = append(g.buildings, b)
+	Entity
 }
 
-func (g *Game) RemoveBuilding(b Building) {
-	for i, building := range g.buildings {
-		if building == b {
-			g.buildings = append(g.buildings[:i], g.buildings[i+1:]...)
-			return
-		}
+// newBuildingView はシミュレーション上の建物 m を描画するための Building を作る
+// セーブデータから再開したときのように、すでに World に置かれている建物に使う
+func newBuildingView(g *Game, m *sim.Building) Building {
+	switch m.Kind {
+	case sim.BuildingBarricade:
+		b := newBarricade(g, m.X, m.Y)
+		b.model = m
+		return b
+	case sim.BuildingTower:
+		t := newTower(g, m.X, m.Y)
+		t.model = m
+		return t
+	case sim.BuildingRadioTower:
+		rt := newRadioTower(g, m.X, m.Y)
+		rt.model = m
+		return rt
 	}
+	log.Fatal("invalid building kind")
+	return nil
 }
@@ -19,52 +19,24 @@ type buildPane struct {
 	okButton     *Button
 	cancelButton *Button
 
-	readyButton *Button
+	// マウスのカーソルが乗っているかどうか
+	// 乗っている間は建築予定のものがカーソルについてくる
+	hovering bool
 }
 
 func newBuildPane(game *Game) *buildPane {
 	okButton := newButton(game, screenWidth-200-22, eScreenHeight-80, 100, 50, 110,
 		func(x, y int) bool {
-			if game.buildCandidate == nil {
-				return true
-			}
-
-			bcX, bcY := game.buildCandidate.Position()
-			if bcX == 0 && bcY == 0 {
+			if !game.hasPlacedBuildCandidate() {
 				// まだ場所が決まっていない場合はボタンを無効にする
 				return true
 			}
 
 			// 建築不可能な場所を指定していた場合は何もしない
-			if game.buildCandidate.IsOverlap() {
-				return false
-			}
-
-			// クリックされたら建築を確定する
-			game.AddBuilding(game.buildCandidate)
-			game.clickHandler.Add(game.buildCandidate)
-			game.updateHandler.Add(game.buildCandidate)
-
-			// クレジットを減らす
-			game.credit -= game.buildCandidate.Cost()
-
-			// buildCandidate は次の建築のために初期化する
-			game.buildCandidate = nil
-			game.infoPanel.drawDescriptionFn = nil
-
-			getAudioPlayer().play(soundDon)
-
+			game.confirmBuild()
 			return false
 		},
 		func(screen *ebiten.Image, x, y, width, height int) {
-			if game.buildCandidate == nil {
-				return
-			}
-			bcX, bcY := game.buildCandidate.Position()
-			if bcX == 0 && bcY == 0 {
-				// まだ場所が決まっていない場合はボタンを無効にする
-				return
-			}
 			// 置けない場所に建築しようとした場合はボタンをグレーアウトする
 			if game.buildCandidate.IsOverlap() {
 				drawGrayRect(screen, x, y, width, height)
@@ -76,18 +48,14 @@ func newBuildPane(game *Game) *buildPane {
 
 	cancelButton := newButton(game, screenWidth-100-12, eScreenHeight-80, 100, 50, 110,
 		func(x, y int) bool {
-			if game.buildCandidate == nil {
-				return true
-			}
-			bcX, bcY := game.buildCandidate.Position()
-			if bcX == 0 && bcY == 0 {
+			if !game.hasPlacedBuildCandidate() {
 				// まだ場所が決まっていない場合はボタンを無効にする
 				return true
 			}
 
 			// クリックされたら建築をキャンセルする
 
-			game.drawHandler.Remove(game.buildCandidate)
+			game.phaseHandlers.drawHandler.Remove(game.buildCandidate)
 			game.buildCandidate = nil
 			game.infoPanel.drawDescriptionFn = nil
 
@@ -96,21 +64,12 @@ func newBuildPane(game *Game) *buildPane {
 			return false
 		},
 		func(screen *ebiten.Image, x, y, width, height int) {
-			if game.buildCandidate == nil {
-				return
-			}
-			bcX, bcY := game.buildCandidate.Position()
-			if bcX == 0 && bcY == 0 {
-				// まだ場所が決まっていない場合はボタンを無効にする
-				return
-			}
-
 			drawRect(screen, x, y, width, height)
 			ebitenutil.DebugPrintAt(screen, "Cancel", x+width/2-20, y+height/2-8)
 		})
 
-	game.clickHandler.Add(okButton)
-	game.clickHandler.Add(cancelButton)
+	game.phaseHandlers.clickHandler.Add(okButton)
+	game.phaseHandlers.clickHandler.Add(cancelButton)
 
 	return &buildPane{
 		game: game,
@@ -127,6 +86,10 @@ func newBuildPane(game *Game) *buildPane {
 }
 
 func (a *buildPane) Draw(screen *ebiten.Image) {
+	// まだ場所が決まっていない場合はボタンを出さない
+	if !a.game.hasPlacedBuildCandidate() {
+		return
+	}
 	a.okButton.Draw(screen)
 	a.cancelButton.Draw(screen)
 }
@@ -138,14 +101,91 @@ func (a *buildPane) OnClick(x, y int) bool {
 		return true
 	}
 
-	if !a.game.drawHandler.Lookup(a.game.buildCandidate) {
-		a.game.drawHandler.Add(a.game.buildCandidate)
+	a.moveBuildCandidate(x, y)
+
+	// マウスのときはカーソルについてきているものをそのまま建てる
+	// タッチのときは場所を決めるだけで、BUILD IT! ボタンで建てる
+	if a.hovering && a.game.confirmBuild() {
+		return false
+	}
+
+	return true
+}
+
+// buildPane implements Hoverable interface
+func (a *buildPane) OnHoverEnter(x, y int) bool {
+	a.hovering = true
+	a.OnHoverMove(x, y)
+	return true
+}
+
+func (a *buildPane) OnHoverMove(x, y int) {
+	if a.game.buildCandidate == nil {
+		return
+	}
+	a.moveBuildCandidate(x, y)
+}
+
+func (a *buildPane) OnHoverLeave() {
+	a.hovering = false
+}
+
+// buildPane implements Draggable interface
+// 建築予定のものを持っているときは、ドラッグで動かせる (タッチのため)
+func (a *buildPane) OnDragStart(x, y int) bool {
+	return a.game.buildCandidate == nil
+}
+
+func (a *buildPane) OnDragMove(x, y int) {
+	if a.game.buildCandidate == nil {
+		return
+	}
+	a.moveBuildCandidate(x, y)
+}
+
+func (a *buildPane) OnDragEnd(x, y int) {
+	a.OnDragMove(x, y)
+}
+
+// 建築予定のものを (x, y) に動かす
+func (a *buildPane) moveBuildCandidate(x, y int) {
+	if !a.game.phaseHandlers.drawHandler.Lookup(a.game.buildCandidate) {
+		a.game.phaseHandlers.drawHandler.Add(a.game.buildCandidate)
 	}
 
 	a.game.buildCandidate.SetPosition(x, y)
 
 	// 他の建築物と重なっているかどうか判定してフラグをセットする
 	a.game.buildCandidate.SetOverlap(a.game.buildCandidate.IsOverlap())
+}
+
+// 建築予定のものを持っていて、その場所が決まっているかどうか
+func (g *Game) hasPlacedBuildCandidate() bool {
+	if g.buildCandidate == nil {
+		return false
+	}
+	x, y := g.buildCandidate.Position()
+	return x != 0 || y != 0
+}
+
+// confirmBuild は建築予定のものをいまの場所に建てる
+// 建築不可能な場所を指定していた場合は何もせずに false を返す
+func (g *Game) confirmBuild() bool {
+	if !g.hasPlacedBuildCandidate() || !g.world.CanBuild(g.buildCandidate.Model()) {
+		return false
+	}
+
+	// 建築を確定する
+	// クレジットもここで減る
+	g.world.Build(g.buildCandidate.Model())
+
+	// 建築予定の間はフェーズのハンドラで描画していたので、ゲーム本編のハンドラに移す
+	g.phaseHandlers.drawHandler.Remove(g.buildCandidate)
+	g.entities.Register(g.buildCandidate.Model().ID, g.buildCandidate)
+
+	// buildCandidate は次の建築のために初期化する
+	g.buildCandidate = nil
+	g.infoPanel.drawDescriptionFn = nil
 
 	return true
 }
@@ -157,13 +197,3 @@ func (a *buildPane) IsClicked(x, y int) bool {
 func (a *buildPane) ZIndex() int {
 	return a.zindex
 }
-
-// ウェーブフェーズに繊維するとき、建築フェーズのパネルを取り去る
-// このとき、ClickHandler や DrawHandler に入れたボタンも取り去る
-func (p *buildPane) RemoveAll() {
-	p.game.clickHandler.Remove(p.okButton)
-	p.game.clickHandler.Remove(p.cancelButton)
-	p.game.clickHandler.Remove(p.readyButton)
-	p.game.drawHandler.Remove(p)
-	p.game.clickHandler.Remove(p)
-}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"image/color"
+
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
 type Button struct {
@@ -13,6 +16,9 @@ type Button struct {
 
 	onClick func(x, y int) bool
 	onDraw  func(screen *ebiten.Image, x, y, width, height int)
+
+	// カーソルが乗っているかどうか
+	hovered bool
 }
 
 func newButton(g *Game, x, y, width, height, zindex int, clickFn func(x, y int) bool, drawFn func(screen *ebiten.Image, x, y, width, height int)) *Button {
@@ -40,6 +46,24 @@ func (b *Button) IsClicked(x, y int) bool {
 
 func (b *Button) Draw(screen *ebiten.Image) {
 	b.onDraw(screen, b.x, b.y, b.width, b.height)
+
+	// カーソルが乗っているときは少し明るくする
+	if b.hovered {
+		vector.DrawFilledRect(screen, float32(b.x), float32(b.y), float32(b.width), float32(b.height), color.RGBA{0xff, 0xff, 0xff, 0x30}, true)
+	}
+}
+
+// Button implements Hoverable interface
+// ボタンの下にあるものにはカーソルが乗らない
+func (b *Button) OnHoverEnter(x, y int) bool {
+	b.hovered = true
+	return false
+}
+
+func (b *Button) OnHoverMove(x, y int) {}
+
+func (b *Button) OnHoverLeave() {
+	b.hovered = false
 }
 
 func (b *Button) ZIndex() int {
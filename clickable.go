@@ -10,12 +10,56 @@ type Clickable interface {
 	ZIndex() int
 }
 
+// 以下は Clickable が必要に応じて実装するもの
+// どれも IsClicked で当たっているかを判定し、ZIndex の大きい順に呼ばれる
+// bool を返すものは、OnClick と同じく true なら重なっている次のオブジェクトにも渡る
+
+// Hoverable はカーソルが乗ったことを受け取る
+type Hoverable interface {
+	// カーソルが乗ったときに呼ばれる
+	// ここで返した値は、カーソルが乗っている間ずっと使われる
+	OnHoverEnter(x, y int) bool
+	// 乗っている間にカーソルが動いたときに呼ばれる
+	OnHoverMove(x, y int)
+	OnHoverLeave()
+}
+
+// Pressable は押されたことと離されたことを受け取る
+type Pressable interface {
+	OnPress(x, y int) bool
+	OnRelease(x, y int) bool
+}
+
+// Draggable はドラッグを受け取る
+// OnDragStart で false を返したものが、ドラッグが終わるまで OnDragMove と OnDragEnd を受け取る
+type Draggable interface {
+	OnDragStart(x, y int) bool
+	OnDragMove(x, y int)
+	OnDragEnd(x, y int)
+}
+
 type OnClickHandler struct {
 	// List of clickable objects
 	clickableObjects []Clickable
+
+	// HandleClick の最中かどうか
+	// 最中に Add/Remove されたものは pending にためておき、HandleClick の最後に反映する
+	dispatching bool
+	pending     pendingQueue[Clickable]
+
+	// カーソルが乗っているものと、乗ったときに OnHoverEnter が返した値
+	hovered map[Clickable]bool
+	// ドラッグを受け取っているもの
+	dragging Draggable
 }
 
 func (o *OnClickHandler) Add(obj Clickable) {
+	if o.dispatching {
+		// 追加されたものはいま処理中のクリックを受け取らない
+		o.pending.add(obj)
+		return
+	}
+
 	o.clickableObjects = append(o.clickableObjects, obj)
 
 	// ZIndex でソートしておく
@@ -26,6 +70,17 @@ func (o *OnClickHandler) Add(obj Clickable) {
 }
 
 func (o *OnClickHandler) Remove(obj Clickable) {
+	if o.dispatching {
+		o.pending.remove(obj)
+		return
+	}
+
+	// 取り除かれたものにはもう何も届けない
+	delete(o.hovered, obj)
+	if d, ok := obj.(Draggable); ok && o.dragging == d {
+		o.dragging = nil
+	}
+
 	for i, v := range o.clickableObjects {
 		if v == obj {
 			o.clickableObjects = append(o.clickableObjects[:i], o.clickableObjects[i+1:]...)
@@ -35,15 +90,145 @@ func (o *OnClickHandler) Remove(obj Clickable) {
 }
 
 func (o *OnClickHandler) Clear() {
+	o.pending.reset()
+	if o.dispatching {
+		// 処理中のものも含めてすべて取り除く
+		for _, v := range o.clickableObjects {
+			o.pending.remove(v)
+		}
+		return
+	}
 	o.clickableObjects = []Clickable{}
+	o.hovered = nil
+	o.dragging = nil
 }
 
-func (o *OnClickHandler) HandleClick(x, y int) {
+// クリックが最後まで貫通したら true を返す
+func (o *OnClickHandler) HandleClick(x, y int) bool {
+	o.dispatching = true
+	defer func() {
+		o.dispatching = false
+		o.pending.flush(o.Add, o.Remove)
+	}()
+
 	for _, obj := range o.clickableObjects {
+		// 処理中に取り除かれたものはクリックを受け取らない
+		if o.pending.isRemoved(obj) {
+			continue
+		}
 		if obj.IsClicked(x, y) {
 			if !obj.OnClick(x, y) {
-				return
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ポインタのイベントが最後まで貫通したら true を返す
+func (o *OnClickHandler) HandlePointer(e pointerEvent) bool {
+	o.dispatching = true
+	defer func() {
+		o.dispatching = false
+		o.pending.flush(o.Add, o.Remove)
+	}()
+
+	switch e.kind {
+	case pointerHover:
+		return o.handleHover(e.x, e.y)
+	case pointerLeave:
+		o.leaveAll(nil)
+		return true
+	case pointerPress:
+		return o.each(e.x, e.y, func(obj Clickable) bool {
+			if p, ok := obj.(Pressable); ok {
+				return p.OnPress(e.x, e.y)
+			}
+			return true
+		})
+	case pointerRelease:
+		return o.each(e.x, e.y, func(obj Clickable) bool {
+			if p, ok := obj.(Pressable); ok {
+				return p.OnRelease(e.x, e.y)
+			}
+			return true
+		})
+	case pointerDragStart:
+		return o.each(e.x, e.y, func(obj Clickable) bool {
+			d, ok := obj.(Draggable)
+			if !ok || d.OnDragStart(e.x, e.y) {
+				return true
 			}
+			o.dragging = d
+			return false
+		})
+	case pointerDragMove:
+		if o.dragging == nil {
+			return true
+		}
+		o.dragging.OnDragMove(e.x, e.y)
+		return false
+	case pointerDragEnd:
+		if o.dragging == nil {
+			return true
+		}
+		d := o.dragging
+		o.dragging = nil
+		d.OnDragEnd(e.x, e.y)
+		return false
+	}
+	return true
+}
+
+// (x, y) に当たっているものに ZIndex の大きい順に fn を呼ぶ
+// fn が false を返したらそこでやめて false を返す
+func (o *OnClickHandler) each(x, y int, fn func(obj Clickable) bool) bool {
+	for _, obj := range o.clickableObjects {
+		if o.pending.isRemoved(obj) {
+			continue
+		}
+		if obj.IsClicked(x, y) && !fn(obj) {
+			return false
+		}
+	}
+	return true
+}
+
+func (o *OnClickHandler) handleHover(x, y int) bool {
+	current := map[Clickable]bool{}
+	passed := o.each(x, y, func(obj Clickable) bool {
+		h, ok := obj.(Hoverable)
+		if !ok {
+			// Hoverable でないものはカーソルをさえぎらない
+			return true
+		}
+
+		through, already := o.hovered[obj]
+		if already {
+			h.OnHoverMove(x, y)
+		} else {
+			through = h.OnHoverEnter(x, y)
+		}
+		current[obj] = through
+		return through
+	})
+
+	o.leaveAll(current)
+	o.hovered = current
+
+	return passed
+}
+
+// keep に含まれないものすべてについて、カーソルが離れたことにする
+func (o *OnClickHandler) leaveAll(keep map[Clickable]bool) {
+	for _, obj := range o.clickableObjects {
+		if _, ok := o.hovered[obj]; !ok {
+			continue
+		}
+		if _, ok := keep[obj]; ok {
+			continue
 		}
+		obj.(Hoverable).OnHoverLeave()
 	}
+	o.hovered = keep
 }
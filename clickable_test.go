@@ -90,3 +90,204 @@ func TestOnClickHandlerHandleClickNoClick(t *testing.T) {
 		t.Errorf("obj1 should not be clicked because click was outside the bounds")
 	}
 }
+
+// SelfRemovingClickable removes itself from the handler when clicked.
+type SelfRemovingClickable struct {
+	MockClickable
+	handler *OnClickHandler
+}
+
+func (m *SelfRemovingClickable) OnClick(x, y int) bool {
+	m.clicked = true
+	m.handler.Remove(m)
+	return true
+}
+
+func TestOnClickHandlerRemoveDuringClick(t *testing.T) {
+	handler := &OnClickHandler{}
+	obj1 := &SelfRemovingClickable{MockClickable: MockClickable{x: 0, y: 0, width: 10, height: 10, zIndex: 2}, handler: handler}
+	obj2 := &MockClickable{x: 0, y: 0, width: 10, height: 10, zIndex: 1}
+
+	handler.Add(obj1)
+	handler.Add(obj2)
+
+	handler.HandleClick(5, 5)
+
+	if !obj1.clicked {
+		t.Errorf("obj1 should be clicked")
+	}
+	if !obj2.clicked {
+		t.Errorf("obj2 should be clicked even though obj1 removed itself")
+	}
+	if len(handler.clickableObjects) != 1 || handler.clickableObjects[0] != obj2 {
+		t.Errorf("expected only obj2 to remain after the click")
+	}
+}
+
+// SpawningClickable adds another clickable to the handler when clicked.
+type SpawningClickable struct {
+	MockClickable
+	handler *OnClickHandler
+	spawned *MockClickable
+}
+
+func (m *SpawningClickable) OnClick(x, y int) bool {
+	m.clicked = true
+	m.handler.Add(m.spawned)
+	return true
+}
+
+func TestOnClickHandlerAddDuringClick(t *testing.T) {
+	handler := &OnClickHandler{}
+	spawned := &MockClickable{x: 0, y: 0, width: 10, height: 10, zIndex: 1}
+	obj1 := &SpawningClickable{MockClickable: MockClickable{x: 0, y: 0, width: 10, height: 10, zIndex: 2}, handler: handler, spawned: spawned}
+
+	handler.Add(obj1)
+
+	handler.HandleClick(5, 5)
+
+	if spawned.clicked {
+		t.Errorf("object added during the click should not receive the same click")
+	}
+	if len(handler.clickableObjects) != 2 {
+		t.Errorf("expected 2 objects after the click, got %d", len(handler.clickableObjects))
+	}
+
+	handler.HandleClick(5, 5)
+
+	if !spawned.clicked {
+		t.Errorf("object added during the previous click should receive the next click")
+	}
+}
+
+// MockPointerTarget is a mock Clickable that also receives hover, press and drag events.
+type MockPointerTarget struct {
+	MockClickable
+	passThrough bool
+
+	hovered               bool
+	hoverEnter, hoverMove int
+	pressed, released     int
+	dragStart, dragMove   int
+	dragEnd               int
+	lastDragX, lastDragY  int
+}
+
+func (m *MockPointerTarget) OnHoverEnter(x, y int) bool {
+	m.hovered = true
+	m.hoverEnter++
+	return m.passThrough
+}
+func (m *MockPointerTarget) OnHoverMove(x, y int) { m.hoverMove++ }
+func (m *MockPointerTarget) OnHoverLeave()        { m.hovered = false }
+func (m *MockPointerTarget) OnPress(x, y int) bool {
+	m.pressed++
+	return m.passThrough
+}
+func (m *MockPointerTarget) OnRelease(x, y int) bool {
+	m.released++
+	return m.passThrough
+}
+func (m *MockPointerTarget) OnDragStart(x, y int) bool {
+	m.dragStart++
+	return m.passThrough
+}
+func (m *MockPointerTarget) OnDragMove(x, y int) {
+	m.dragMove++
+	m.lastDragX, m.lastDragY = x, y
+}
+func (m *MockPointerTarget) OnDragEnd(x, y int) { m.dragEnd++ }
+
+func TestOnClickHandlerHover(t *testing.T) {
+	handler := &OnClickHandler{}
+	top := &MockPointerTarget{MockClickable: MockClickable{x: 0, y: 0, width: 10, height: 10, zIndex: 2}}
+	bottom := &MockPointerTarget{MockClickable: MockClickable{x: 0, y: 0, width: 20, height: 20, zIndex: 1}}
+
+	handler.Add(top)
+	handler.Add(bottom)
+
+	// top がカーソルをさえぎる
+	handler.HandlePointer(pointerEvent{kind: pointerHover, x: 5, y: 5})
+	if !top.hovered || bottom.hovered {
+		t.Errorf("expected only the top object to be hovered")
+	}
+
+	// 乗ったまま動いたら enter はもう呼ばれない
+	handler.HandlePointer(pointerEvent{kind: pointerHover, x: 6, y: 6})
+	if top.hoverEnter != 1 || top.hoverMove != 1 {
+		t.Errorf("expected one enter and one move, got %d, %d", top.hoverEnter, top.hoverMove)
+	}
+
+	// top から外れると bottom に乗る
+	handler.HandlePointer(pointerEvent{kind: pointerHover, x: 15, y: 15})
+	if top.hovered || !bottom.hovered {
+		t.Errorf("expected hover to move from top to bottom")
+	}
+
+	handler.HandlePointer(pointerEvent{kind: pointerLeave})
+	if top.hovered || bottom.hovered {
+		t.Errorf("expected every object to be left")
+	}
+}
+
+func TestOnClickHandlerHoverPassThrough(t *testing.T) {
+	handler := &OnClickHandler{}
+	top := &MockPointerTarget{MockClickable: MockClickable{x: 0, y: 0, width: 10, height: 10, zIndex: 2}, passThrough: true}
+	bottom := &MockPointerTarget{MockClickable: MockClickable{x: 0, y: 0, width: 10, height: 10, zIndex: 1}}
+
+	handler.Add(top)
+	handler.Add(bottom)
+
+	if handler.HandlePointer(pointerEvent{kind: pointerHover, x: 5, y: 5}) {
+		t.Errorf("expected hover to be blocked by the bottom object")
+	}
+	if !top.hovered || !bottom.hovered {
+		t.Errorf("expected both objects to be hovered")
+	}
+}
+
+func TestOnClickHandlerPressRelease(t *testing.T) {
+	handler := &OnClickHandler{}
+	top := &MockPointerTarget{MockClickable: MockClickable{x: 0, y: 0, width: 10, height: 10, zIndex: 2}}
+	bottom := &MockPointerTarget{MockClickable: MockClickable{x: 0, y: 0, width: 10, height: 10, zIndex: 1}}
+
+	handler.Add(top)
+	handler.Add(bottom)
+
+	handler.HandlePointer(pointerEvent{kind: pointerPress, x: 5, y: 5})
+	handler.HandlePointer(pointerEvent{kind: pointerRelease, x: 5, y: 5})
+
+	if top.pressed != 1 || top.released != 1 {
+		t.Errorf("expected top to be pressed and released")
+	}
+	if bottom.pressed != 0 || bottom.released != 0 {
+		t.Errorf("expected bottom not to receive press and release")
+	}
+}
+
+func TestOnClickHandlerDrag(t *testing.T) {
+	handler := &OnClickHandler{}
+	obj := &MockPointerTarget{MockClickable: MockClickable{x: 0, y: 0, width: 10, height: 10, zIndex: 1}}
+
+	handler.Add(obj)
+
+	handler.HandlePointer(pointerEvent{kind: pointerDragStart, x: 5, y: 5})
+	// ドラッグが始まったら、外に出ても同じものが受け取る
+	handler.HandlePointer(pointerEvent{kind: pointerDragMove, x: 100, y: 100})
+	handler.HandlePointer(pointerEvent{kind: pointerDragEnd, x: 100, y: 100})
+
+	if obj.dragStart != 1 || obj.dragMove != 1 || obj.dragEnd != 1 {
+		t.Errorf("expected one drag start, move and end, got %d, %d, %d", obj.dragStart, obj.dragMove, obj.dragEnd)
+	}
+	if obj.lastDragX != 100 || obj.lastDragY != 100 {
+		t.Errorf("expected drag to move to (100, 100), got (%d, %d)", obj.lastDragX, obj.lastDragY)
+	}
+
+	// ドラッグが終わったらもう受け取らない
+	if !handler.HandlePointer(pointerEvent{kind: pointerDragMove, x: 5, y: 5}) {
+		t.Errorf("expected drag move without a drag to pass through")
+	}
+	if obj.dragMove != 1 {
+		t.Errorf("expected no more drag moves after the drag ended")
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+
+	"github.com/pankona/gj/sim"
+)
+
+func TestNewDistribution(t *testing.T) {
+	d := newDistribution([]int{5, 1, 3, 2, 4})
+	want := distribution{Min: 1, P25: 2, Median: 3, P75: 4, Max: 5, Mean: 3}
+	if d != want {
+		t.Errorf("expected %+v, got %+v", want, d)
+	}
+
+	if newDistribution(nil) != (distribution{}) {
+		t.Errorf("expected zero distribution for no values")
+	}
+}
+
+func TestRunAllDeterministic(t *testing.T) {
+	s, ok := findStrategy("mixed")
+	if !ok {
+		t.Fatal("mixed strategy not found")
+	}
+
+	// 同時に進める数を変えても、同じシードなら同じ結果になる
+	c := runConfig{strategy: s, slap: true, difficulty: sim.DifficultyNormal}
+	a := runAll(c, 1, 3, 1)
+	b := runAll(c, 1, 3, 3)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("expected the same results regardless of parallelism")
+	}
+}
+
+func TestRunWithoutDefense(t *testing.T) {
+	s, _ := findStrategy("none")
+	r := run(runConfig{seed: 1, strategy: s, difficulty: sim.DifficultyNormal})
+
+	if r.allClear {
+		t.Fatalf("expected the house to fall without any defense")
+	}
+	last := r.waves[len(r.waves)-1]
+	if last.cleared || last.houseHealth != 0 {
+		t.Errorf("expected the last wave to be lost, got %+v", last)
+	}
+	// 何も建てないのでクレジットは増える一方
+	for i := 1; i < len(r.waves); i++ {
+		if r.waves[i].creditAtStart <= r.waves[i-1].creditAtStart {
+			t.Errorf("expected credit to grow at wave %d", i)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	s, _ := findStrategy("none")
+	results := []runResult{run(runConfig{seed: 1, strategy: s, difficulty: sim.DifficultyNormal})}
+	r := report{Strategies: []strategyReport{newStrategyReport("none", false, 3, results)}}
+
+	var buf bytes.Buffer
+	if err := writeCSV(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ヘッダとウェーブの数だけ行がある
+	if len(rows) != 1+3 {
+		t.Errorf("expected 4 rows, got %d", len(rows))
+	}
+}
+
+func TestAutoSlapIgnoresOffFieldBugs(t *testing.T) {
+	w := sim.NewWorld(1)
+	hx, _ := w.House.Position()
+
+	// 画面の上から出てくるところで、家には一番近い
+	w.AddBug(sim.NewBug(sim.BugRed, hx, -50))
+	autoSlap(w)
+	if w.Hand.Visible() {
+		t.Fatalf("expected a bug outside the field not to be slapped")
+	}
+
+	// 画面の中にいる虫は遠くても叩く
+	b := sim.NewBug(sim.BugRed, 10, 10)
+	w.AddBug(b)
+	autoSlap(w)
+	if !w.Hand.Visible() || w.Hand.X != b.X || w.Hand.Y != b.Y {
+		t.Errorf("expected the bug on the field to be slapped, got hand at (%d, %d)", w.Hand.X, w.Hand.Y)
+	}
+}
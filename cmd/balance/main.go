@@ -0,0 +1,146 @@
+// balance はウィンドウを出さずにゲームを何度も進めて、バランス調整のための数字を集める
+//
+// 建築の戦略ごとに、シードを変えながら -runs 回ずつゲームを進め、
+// ウェーブごとの生存率、家の体力の分布、クレジットの推移を JSON か CSV で書き出す
+//
+//	go run ./cmd/balance -runs 1000 -format csv > balance.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/pankona/gj/sim"
+)
+
+func main() {
+	runs := flag.Int("runs", 1000, "number of runs for each strategy")
+	seed := flag.Int64("seed", 1, "seed of the first run. run i uses seed+i")
+	strategyNames := flag.String("strategy", "all", "comma separated strategies to run ("+strategyList()+") or all")
+	slap := flag.Bool("slap", true, "slap the bug nearest to the house whenever the hand is ready")
+	format := flag.String("format", "json", "output format (json or csv)")
+	output := flag.String("o", "", "output file (stdout if empty)")
+	parallel := flag.Int("parallel", runtime.NumCPU(), "number of runs to simulate at the same time")
+	statsFile := flag.String("stats", "", "file to override the unit and building stats")
+	difficultyName := flag.String("difficulty", "normal", "difficulty (easy, normal or hard)")
+	flag.Parse()
+
+	difficulty, ok := difficulties[*difficultyName]
+	if !ok {
+		log.Fatalf("unknown difficulty: %s", *difficultyName)
+	}
+
+	// 能力値を変えたときの結果を見られるように、ゲーム本体と同じく上書きできる
+	if *statsFile != "" {
+		b, err := os.ReadFile(*statsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := sim.LoadStats(b); err != nil {
+			log.Fatalf("invalid stats file: %v", err)
+		}
+	}
+
+	targets, err := parseStrategies(*strategyNames)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var write func(io.Writer, report) error
+	switch *format {
+	case "json":
+		write = writeJSON
+	case "csv":
+		write = writeCSV
+	default:
+		log.Fatalf("unknown format: %s", *format)
+	}
+
+	waveCount := sim.NewWorld(*seed).WaveCount()
+	r := report{BaseSeed: *seed, Difficulty: difficulty.Name}
+	for _, s := range targets {
+		results := runAll(runConfig{strategy: s, slap: *slap, difficulty: difficulty}, *seed, *runs, *parallel)
+		r.Strategies = append(r.Strategies, newStrategyReport(s.name, *slap, waveCount, results))
+	}
+
+	out := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := write(out, r); err != nil {
+		log.Fatal(err)
+	}
+}
+
+var difficulties = map[string]sim.Difficulty{
+	"easy":   sim.DifficultyEasy,
+	"normal": sim.DifficultyNormal,
+	"hard":   sim.DifficultyHard,
+}
+
+func strategyList() string {
+	var names []string
+	for _, s := range strategies {
+		names = append(names, s.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func parseStrategies(names string) ([]strategy, error) {
+	if names == "all" {
+		return strategies, nil
+	}
+
+	var targets []strategy
+	for _, name := range strings.Split(names, ",") {
+		s, ok := findStrategy(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown strategy: %s", name)
+		}
+		targets = append(targets, s)
+	}
+	return targets, nil
+}
+
+// runAll は c の進め方で、シードを変えながら runs 回ゲームを進める
+// ゲームどうしは独立しているので、parallel 個ずつ同時に進める
+// 結果はシードの順に並ぶので、parallel を変えても同じ結果になる
+func runAll(c runConfig, seed int64, runs int, parallel int) []runResult {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	results := make([]runResult, runs)
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				c := c
+				c.seed = seed + int64(i)
+				results[i] = run(c)
+			}
+		}()
+	}
+	for i := 0; i < runs; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
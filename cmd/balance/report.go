@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// distribution は値の分布
+type distribution struct {
+	Min    int     `json:"min"`
+	P25    int     `json:"p25"`
+	Median int     `json:"median"`
+	P75    int     `json:"p75"`
+	Max    int     `json:"max"`
+	Mean   float64 `json:"mean"`
+}
+
+func newDistribution(values []int) distribution {
+	if len(values) == 0 {
+		return distribution{}
+	}
+
+	sorted := append([]int{}, values...)
+	sort.Ints(sorted)
+
+	sum := 0
+	for _, v := range sorted {
+		sum += v
+	}
+	at := func(p float64) int {
+		return sorted[int(p*float64(len(sorted)-1))]
+	}
+
+	return distribution{
+		Min:    sorted[0],
+		P25:    at(0.25),
+		Median: at(0.5),
+		P75:    at(0.75),
+		Max:    sorted[len(sorted)-1],
+		Mean:   float64(sum) / float64(len(sorted)),
+	}
+}
+
+// waveReport はあるウェーブについての集計
+type waveReport struct {
+	// 0 始まりのウェーブ番号
+	Wave int `json:"wave"`
+	// このウェーブまでたどりついたゲームの数
+	Reached int `json:"reached"`
+	// このウェーブをクリアしたゲームの数
+	Cleared int `json:"cleared"`
+	// Cleared / Reached
+	SurvivalRate float64 `json:"survivalRate"`
+
+	// クリアしたときの家の体力
+	HouseHealth distribution `json:"houseHealth"`
+	// ウェーブを始めたときのクレジット (建築したあと)
+	CreditAtStart distribution `json:"creditAtStart"`
+	// ウェーブを終えたときのクレジット
+	CreditAtEnd distribution `json:"creditAtEnd"`
+	// ウェーブが終わるまでのフレーム数
+	Frames distribution `json:"frames"`
+}
+
+// strategyReport はひとつの建築戦略についての集計
+type strategyReport struct {
+	Strategy string `json:"strategy"`
+	Slap     bool   `json:"slap"`
+	Runs     int    `json:"runs"`
+	// 最後までクリアしたゲームの割合
+	ClearRate float64      `json:"clearRate"`
+	Waves     []waveReport `json:"waves"`
+}
+
+type report struct {
+	BaseSeed   int64            `json:"baseSeed"`
+	Difficulty string           `json:"difficulty"`
+	Strategies []strategyReport `json:"strategies"`
+}
+
+func newStrategyReport(name string, slap bool, waveCount int, results []runResult) strategyReport {
+	r := strategyReport{
+		Strategy: name,
+		Slap:     slap,
+		Runs:     len(results),
+	}
+
+	allClear := 0
+	for _, res := range results {
+		if res.allClear {
+			allClear++
+		}
+	}
+	if len(results) > 0 {
+		r.ClearRate = float64(allClear) / float64(len(results))
+	}
+
+	for wave := 0; wave < waveCount; wave++ {
+		wr := waveReport{Wave: wave}
+		var health, creditAtStart, creditAtEnd, frames []int
+		for _, res := range results {
+			if wave >= len(res.waves) {
+				continue
+			}
+			w := res.waves[wave]
+			wr.Reached++
+			creditAtStart = append(creditAtStart, w.creditAtStart)
+			creditAtEnd = append(creditAtEnd, w.creditAtEnd)
+			frames = append(frames, w.frames)
+			if w.cleared {
+				wr.Cleared++
+				health = append(health, w.houseHealth)
+			}
+		}
+		if wr.Reached > 0 {
+			wr.SurvivalRate = float64(wr.Cleared) / float64(wr.Reached)
+		}
+		wr.HouseHealth = newDistribution(health)
+		wr.CreditAtStart = newDistribution(creditAtStart)
+		wr.CreditAtEnd = newDistribution(creditAtEnd)
+		wr.Frames = newDistribution(frames)
+		r.Waves = append(r.Waves, wr)
+	}
+
+	return r
+}
+
+func writeJSON(out io.Writer, r report) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// writeCSV は 1 行に 1 ウェーブずつ書き出す
+func writeCSV(out io.Writer, r report) error {
+	w := csv.NewWriter(out)
+
+	header := []string{"strategy", "slap", "runs", "clear_rate", "wave", "reached", "cleared", "survival_rate"}
+	for _, name := range []string{"house_health", "credit_at_start", "credit_at_end", "frames"} {
+		header = append(header, name+"_min", name+"_p25", name+"_median", name+"_p75", name+"_max", name+"_mean")
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	itoa := strconv.Itoa
+	ftoa := func(f float64) string { return strconv.FormatFloat(f, 'f', 3, 64) }
+	for _, s := range r.Strategies {
+		for _, wr := range s.Waves {
+			row := []string{s.Strategy, strconv.FormatBool(s.Slap), itoa(s.Runs), ftoa(s.ClearRate), itoa(wr.Wave), itoa(wr.Reached), itoa(wr.Cleared), ftoa(wr.SurvivalRate)}
+			for _, d := range []distribution{wr.HouseHealth, wr.CreditAtStart, wr.CreditAtEnd, wr.Frames} {
+				row = append(row, itoa(d.Min), itoa(d.P25), itoa(d.Median), itoa(d.P75), itoa(d.Max), ftoa(d.Mean))
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,73 @@
+package main
+
+import "github.com/pankona/gj/sim"
+
+// 1 ウェーブをこのフレーム数で打ち切る
+// 虫が家にたどりつけずにウェーブが終わらないときのため
+const maxWaveFrame = 60 * 60 * 5
+
+// runConfig はひとつのゲームの進め方
+type runConfig struct {
+	seed     int64
+	strategy strategy
+	slap     bool
+
+	difficulty sim.Difficulty
+}
+
+// waveResult はひとつのウェーブの結果
+type waveResult struct {
+	// ウェーブを始めたときのクレジット (建築したあと)
+	creditAtStart int
+	// ウェーブを終えたときのクレジット
+	creditAtEnd int
+	// ウェーブを終えたときの家の体力
+	houseHealth int
+	cleared     bool
+	// ウェーブが終わるまでのフレーム数
+	frames int
+}
+
+// runResult はひとつのゲームの結果
+// waves は到達したウェーブの数だけある
+type runResult struct {
+	waves    []waveResult
+	allClear bool
+}
+
+// run はウィンドウを出さずに、ゲームオーバーかクリアになるまでゲームを進める
+func run(c runConfig) runResult {
+	w := sim.NewWorld(c.seed)
+	w.SetDifficulty(c.difficulty)
+
+	var r runResult
+	next := 0
+	for w.Wave < w.WaveCount() {
+		c.strategy.build(w, &next)
+
+		wave := w.Wave
+		res := waveResult{creditAtStart: w.Credit}
+
+		w.StartWave()
+		for w.WaveRunning() && res.frames < maxWaveFrame {
+			if c.slap {
+				autoSlap(w)
+			}
+			w.Step()
+			w.Events.Dispatch()
+			res.frames++
+		}
+
+		res.creditAtEnd = w.Credit
+		res.houseHealth = w.House.Health
+		res.cleared = w.Wave > wave
+		r.waves = append(r.waves, res)
+
+		if !res.cleared {
+			return r
+		}
+	}
+
+	r.allClear = true
+	return r
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"math"
+
+	"github.com/pankona/gj/sim"
+)
+
+// strategy は建築フェーズに何をどこに建てるかを決める
+type strategy struct {
+	name string
+
+	// 建てるものの順番
+	// 先頭から順に、クレジットが足りるかぎり建てていく
+	// 足りなくなったら、そのフェーズはそこで建てるのをやめる
+	order []sim.BuildingKind
+}
+
+var strategies = []strategy{
+	{name: "none"},
+	{name: "barricade", order: []sim.BuildingKind{sim.BuildingBarricade}},
+	{name: "tower", order: []sim.BuildingKind{sim.BuildingTower}},
+	{name: "radiotower", order: []sim.BuildingKind{sim.BuildingRadioTower}},
+	{name: "mixed", order: []sim.BuildingKind{sim.BuildingTower, sim.BuildingBarricade, sim.BuildingTower, sim.BuildingRadioTower}},
+}
+
+func findStrategy(name string) (strategy, bool) {
+	for _, s := range strategies {
+		if s.name == name {
+			return s, true
+		}
+	}
+	return strategy{}, false
+}
+
+// build は建築フェーズにクレジットを使って建物を建てる
+// next は order の中で次に建てるものの位置。フェーズをまたいで続きから建てる
+func (s strategy) build(w *sim.World, next *int) {
+	if len(s.order) == 0 {
+		return
+	}
+
+	for {
+		kind := s.order[*next%len(s.order)]
+		b, ok := findPlace(w, kind)
+		if !ok {
+			return
+		}
+		w.Build(b)
+		*next++
+	}
+}
+
+// findPlace は家の近くから順に、kind の建物を建てられる場所を探す
+// クレジットが足りないか、置ける場所がなければ false を返す
+func findPlace(w *sim.World, kind sim.BuildingKind) (*sim.Building, bool) {
+	hx, hy := w.House.Position()
+	b := sim.NewBuilding(kind, hx, hy)
+	if w.Credit < b.Cost() {
+		return nil, false
+	}
+
+	// 家を中心とした円の上に、内側から順に置いてみる
+	for radius := 100; radius < sim.FieldHeight/2; radius += 60 {
+		n := int(2 * math.Pi * float64(radius) / 60)
+		for i := 0; i < n; i++ {
+			angle := 2 * math.Pi * float64(i) / float64(n)
+			x := hx + int(float64(radius)*math.Cos(angle))
+			y := hy + int(float64(radius)*math.Sin(angle))
+			b.SetPosition(x, y)
+			if w.CanBuild(b) {
+				return b, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// autoSlap は家に一番近い虫を叩く
+// 画面の外にいる虫はプレイヤーには叩けないので狙わない
+// クールダウンがあけていなければ何もしない
+func autoSlap(w *sim.World) {
+	hx, hy := w.House.Position()
+
+	var target *sim.Bug
+	nearest := math.MaxFloat64
+	for _, b := range w.Bugs {
+		if b.IsDead() {
+			continue
+		}
+		bx, by := b.Position()
+		if bx < 0 || bx >= sim.ScreenWidth || by < 0 || by >= sim.FieldHeight {
+			continue
+		}
+		d := math.Hypot(float64(bx-hx), float64(by-hy))
+		if d < nearest {
+			nearest = d
+			target = b
+		}
+	}
+	if target == nil {
+		return
+	}
+
+	x, y := target.Position()
+	w.Slap(x, y)
+}
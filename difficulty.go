@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/pankona/gj/sim"
+)
+
+// Custom の難易度を書いておく場所の名前
+// デスクトップでは difficulty.json、ブラウザでは localStorage の gj-difficulty に書く
+// 書かれていない値は Normal のものになる
+const difficultyStorageName = "difficulty"
+
+// loadCustomDifficulty は Custom の難易度を読む
+// なければ Normal と同じ値の Custom を返す
+func loadCustomDifficulty() sim.Difficulty {
+	base := sim.DifficultyNormal
+	base.Name = "Custom"
+
+	b, err := readStorage(difficultyStorageName)
+	if err != nil {
+		return base
+	}
+	d, err := sim.ParseDifficulty(b, base)
+	if err != nil {
+		log.Printf("invalid custom difficulty: %v", err)
+		return base
+	}
+	return d
+}
+
+// タイトルで選べる難易度
+func difficultyChoices() []sim.Difficulty {
+	return []sim.Difficulty{
+		sim.DifficultyEasy,
+		sim.DifficultyNormal,
+		sim.DifficultyHard,
+		loadCustomDifficulty(),
+	}
+}
+
+// nextDifficulty は d の次の難易度を返す
+// 最後までいったら最初に戻る
+func nextDifficulty(d sim.Difficulty) sim.Difficulty {
+	choices := difficultyChoices()
+	for i, c := range choices {
+		if c.Name == d.Name {
+			return choices[(i+1)%len(choices)]
+		}
+	}
+	return choices[0]
+}
+
+// newDifficultyButton は難易度を切り替えるボタンを作る
+func newDifficultyButton(g *Game) *Button {
+	return newButton(g, screenWidth-750, 490, 500, 60, 320,
+		func(x, y int) bool {
+			getAudioPlayer().play(soundChoice)
+			g.difficulty = nextDifficulty(g.difficulty)
+			return false
+		},
+		func(screen *ebiten.Image, x, y, width, height int) {
+			clr := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+			vector.StrokeRect(screen, float32(x), float32(y), float32(width), float32(height), 2, clr, true)
+			drawText(screen, fmt.Sprintf("DIFFICULTY: %s", g.difficulty.Name), x+20, y+15, 3, 3, clr)
+		})
+}
@@ -14,9 +14,19 @@ type Drawable interface {
 type DrawHandler struct {
 	// List of drawable objects
 	drawable []Drawable
+
+	// HandleDraw の最中かどうか
+	// 最中に Add/Remove されたものは pending にためておき、HandleDraw の最後に反映する
+	dispatching bool
+	pending     pendingQueue[Drawable]
 }
 
 func (o *DrawHandler) Add(obj Drawable) {
+	if o.dispatching {
+		o.pending.add(obj)
+		return
+	}
+
 	o.drawable = append(o.drawable, obj)
 
 	// Sort by ZIndex
@@ -28,6 +38,11 @@ func (o *DrawHandler) Add(obj Drawable) {
 }
 
 func (o *DrawHandler) Remove(obj Drawable) {
+	if o.dispatching {
+		o.pending.remove(obj)
+		return
+	}
+
 	for i, v := range o.drawable {
 		if v == obj {
 			o.drawable = append(o.drawable[:i], o.drawable[i+1:]...)
@@ -37,6 +52,11 @@ func (o *DrawHandler) Remove(obj Drawable) {
 }
 
 func (o *DrawHandler) Lookup(obj Drawable) bool {
+	// 反映待ちの操作があればそちらを優先する
+	if removed, ok := o.pending.lookup(obj); ok {
+		return !removed
+	}
+
 	for _, v := range o.drawable {
 		if v == obj {
 			return true
@@ -46,11 +66,27 @@ func (o *DrawHandler) Lookup(obj Drawable) bool {
 }
 
 func (o *DrawHandler) HandleDraw(screen *ebiten.Image) {
+	o.dispatching = true
 	for _, obj := range o.drawable {
+		// このフレームの中ですでに取り除かれたものは描画しない
+		if o.pending.isRemoved(obj) {
+			continue
+		}
 		obj.Draw(screen)
 	}
+	o.dispatching = false
+
+	o.pending.flush(o.Add, o.Remove)
 }
 
 func (o *DrawHandler) Clear() {
+	o.pending.reset()
+	if o.dispatching {
+		// 描画中のものも含めてすべて取り除く
+		for _, v := range o.drawable {
+			o.pending.remove(v)
+		}
+		return
+	}
 	o.drawable = []Drawable{}
 }
@@ -93,3 +93,52 @@ func TestDrawHandlerClear(t *testing.T) {
 		t.Errorf("expected 0 objects, got %d", len(handler.drawable))
 	}
 }
+
+// SelfRemovingDrawable removes itself from the handler when drawn.
+type SelfRemovingDrawable struct {
+	MockDrawable
+	handler *DrawHandler
+}
+
+func (m *SelfRemovingDrawable) Draw(screen *ebiten.Image) {
+	m.drawn = true
+	m.handler.Remove(m)
+}
+
+func TestDrawHandlerRemoveDuringDraw(t *testing.T) {
+	handler := &DrawHandler{}
+	screen := ebiten.NewImage(100, 100)
+
+	obj1 := &SelfRemovingDrawable{MockDrawable: MockDrawable{zIndex: 1}, handler: handler}
+	obj2 := &MockDrawable{zIndex: 2}
+
+	handler.Add(obj1)
+	handler.Add(obj2)
+
+	handler.HandleDraw(screen)
+
+	if !obj1.drawn || !obj2.drawn {
+		t.Errorf("expected both objects to be drawn even though obj1 removed itself")
+	}
+	if len(handler.drawable) != 1 || handler.drawable[0] != obj2 {
+		t.Errorf("expected only obj2 to remain after drawing")
+	}
+	if handler.Lookup(obj1) {
+		t.Errorf("obj1 should not be found after removing itself")
+	}
+}
+
+func TestDrawHandlerLookupPending(t *testing.T) {
+	handler := &DrawHandler{}
+	obj := &MockDrawable{zIndex: 1}
+
+	// 描画中に追加されたものは、反映前でも Lookup で見つかる
+	handler.dispatching = true
+	handler.Add(obj)
+	if !handler.Lookup(obj) {
+		t.Errorf("object added during drawing should be found")
+	}
+	if len(handler.drawable) != 0 {
+		t.Errorf("object added during drawing should not be added until the end of drawing")
+	}
+}
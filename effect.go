@@ -0,0 +1,37 @@
+package main
+
+import "github.com/pankona/gj/sim"
+
+// subscribeEffects はシミュレーションのイベントに応じてエフェクトを描画する
+func (g *Game) subscribeEffects(bus *sim.EventBus) {
+	sim.Subscribe(bus, func(e sim.BugAttacked) {
+		if !e.Ranged {
+			return
+		}
+
+		// 飛び道具を使う虫 (緑虫など) は弾を撃つ
+		tx, ty := e.Target.Position()
+		eff := newGreenBugAttackEffect(g, e.Bug.X, e.Bug.Y, tx, ty)
+		g.updateHandler.Add(eff)
+		g.drawHandler.Add(eff)
+	})
+	sim.Subscribe(bus, func(e sim.BugHealed) {
+		eff := newHealEffect(g, e.Healer.X, e.Healer.Y, e.Target)
+		g.updateHandler.Add(eff)
+		g.drawHandler.Add(eff)
+	})
+	sim.Subscribe(bus, func(e sim.BugExploded) {
+		eff := newBlastEffect(g, e.X, e.Y, e.Radius)
+		g.updateHandler.Add(eff)
+		g.drawHandler.Add(eff)
+	})
+	sim.Subscribe(bus, func(e sim.TowerFired) {
+		// ビームを描画する
+		bm := newBeam(g, e.Tower.X, e.Tower.Y, e.Target.X, e.Target.Y)
+		g.drawHandler.Add(bm)
+	})
+	sim.Subscribe(bus, func(e sim.RadioTowerFired) {
+		eff := newRadioTowerAttackEffect(g, e.Tower.X, e.Tower.Y, e.X, e.Y, e.Tower.AttackZoneRadius)
+		g.drawHandler.Add(eff)
+	})
+}
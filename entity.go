@@ -0,0 +1,89 @@
+package main
+
+import "github.com/pankona/gj/sim"
+
+// Entity はゲーム本編の画面にいる建物や虫の共通のインターフェース
+type Entity interface {
+	Name() string
+	Health() int
+
+	Drawable
+	Clickable
+}
+
+// entityRegistry はゲーム本編の建物や虫を ID で管理する
+// 登録すると描画、クリック、(Updater であれば) 更新のハンドラにまとめて登録され、
+// 取り除くとそれらと情報パネルからまとめて取り除かれる
+// ID はシミュレーション上の建物や虫に割り当てられたものを使う
+type entityRegistry struct {
+	game *Game
+
+	entities map[sim.EntityID]Entity
+}
+
+func newEntityRegistry(g *Game) *entityRegistry {
+	return &entityRegistry{
+		game:     g,
+		entities: map[sim.EntityID]Entity{},
+	}
+}
+
+func (r *entityRegistry) Register(id sim.EntityID, e Entity) {
+	if _, ok := r.entities[id]; ok {
+		// 同じ ID で 2 回登録されると、ハンドラに二重に登録されてしまう
+		r.Remove(id)
+	}
+	r.entities[id] = e
+
+	r.game.drawHandler.Add(e)
+	r.game.clickHandler.Add(e)
+	if u, ok := e.(Updater); ok {
+		r.game.updateHandler.Add(u)
+	}
+}
+
+func (r *entityRegistry) Remove(id sim.EntityID) {
+	e, ok := r.entities[id]
+	if !ok {
+		return
+	}
+	delete(r.entities, id)
+
+	r.game.drawHandler.Remove(e)
+	r.game.clickHandler.Remove(e)
+	if u, ok := e.(Updater); ok {
+		r.game.updateHandler.Remove(u)
+	}
+	r.game.infoPanel.Remove(e)
+}
+
+// Lookup は id で登録されたものを返す
+// 登録されていなければ nil を返す
+func (r *entityRegistry) Lookup(id sim.EntityID) Entity {
+	return r.entities[id]
+}
+
+// Building は id で登録された建物を返す
+// 登録されていないか、建物でなければ nil を返す
+func (r *entityRegistry) Building(id sim.EntityID) Building {
+	b, ok := r.entities[id].(Building)
+	if !ok {
+		return nil
+	}
+	return b
+}
+
+// Enemy は id で登録された虫を返す
+// 登録されていないか、虫でなければ nil を返す
+func (r *entityRegistry) Enemy(id sim.EntityID) Enemy {
+	e, ok := r.entities[id].(Enemy)
+	if !ok {
+		return nil
+	}
+	return e
+}
+
+// Len は登録されているものの数を返す
+func (r *entityRegistry) Len() int {
+	return len(r.entities)
+}
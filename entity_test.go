@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/pankona/gj/sim"
+)
+
+// MockEntity is a mock implementation of the Entity interface for testing purposes.
+type MockEntity struct {
+	MockClickable
+	updated int
+}
+
+func (m *MockEntity) Name() string              { return "Mock" }
+func (m *MockEntity) Health() int               { return 1 }
+func (m *MockEntity) Draw(screen *ebiten.Image) {}
+func (m *MockEntity) ZIndex() int               { return m.zIndex }
+func (m *MockEntity) Update()                   { m.updated++ }
+
+func newTestGameForEntities() *Game {
+	g := &Game{
+		clickHandler:  &OnClickHandler{},
+		drawHandler:   &DrawHandler{},
+		updateHandler: &UpdateHandler{},
+	}
+	g.infoPanel = &infoPanel{game: g}
+	g.entities = newEntityRegistry(g)
+	return g
+}
+
+func TestEntityRegistryRegisterRemove(t *testing.T) {
+	g := newTestGameForEntities()
+	e := &MockEntity{MockClickable: MockClickable{x: 0, y: 0, width: 10, height: 10}}
+
+	g.entities.Register(sim.EntityID(1), e)
+
+	if g.entities.Lookup(1) != e {
+		t.Errorf("expected entity to be found by its ID")
+	}
+	if !g.drawHandler.Lookup(e) {
+		t.Errorf("expected entity to be registered to the draw handler")
+	}
+	if len(g.clickHandler.clickableObjects) != 1 {
+		t.Errorf("expected entity to be registered to the click handler")
+	}
+	if len(g.updateHandler.updaters) != 1 {
+		t.Errorf("expected entity to be registered to the update handler")
+	}
+
+	g.infoPanel.setUnit(e)
+	g.entities.Remove(1)
+
+	if g.entities.Lookup(1) != nil || g.entities.Len() != 0 {
+		t.Errorf("expected entity to be removed")
+	}
+	if g.drawHandler.Lookup(e) {
+		t.Errorf("expected entity to be removed from the draw handler")
+	}
+	if len(g.clickHandler.clickableObjects) != 0 {
+		t.Errorf("expected entity to be removed from the click handler")
+	}
+	if len(g.updateHandler.updaters) != 0 {
+		t.Errorf("expected entity to be removed from the update handler")
+	}
+	if g.infoPanel.unit != nil {
+		t.Errorf("expected entity to be removed from the info panel")
+	}
+
+	// 2 回取り除いても問題ない
+	g.entities.Remove(1)
+}
+
+func TestEntityRegistryTypedLookup(t *testing.T) {
+	g := newTestGameForEntities()
+	e := &MockEntity{}
+
+	g.entities.Register(sim.EntityID(1), e)
+
+	if g.entities.Building(1) != nil {
+		t.Errorf("expected mock entity not to be a building")
+	}
+	if g.entities.Enemy(1) != nil {
+		t.Errorf("expected mock entity not to be an enemy")
+	}
+	if g.entities.Enemy(2) != nil {
+		t.Errorf("expected unknown ID not to be found")
+	}
+}
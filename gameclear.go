@@ -7,6 +7,29 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
+// ゲームクリア画面のシーン
+// ゲーム本編の上に積まれる。後ろではゲーム本編が動き続ける
+type gameclearScene struct {
+	*handlerSet
+}
+
+func newGameClearScene(g *Game) *gameclearScene {
+	s := &gameclearScene{
+		handlerSet: newHandlerSet(),
+	}
+
+	o := newGameClear(g)
+	s.updateHandler.Add(o)
+	s.clickHandler.Add(o)
+	s.drawHandler.Add(o)
+
+	return s
+}
+
+func (s *gameclearScene) UpdatesBelow() bool {
+	return true
+}
+
 type gameclear struct {
 	game         *Game
 	erapsedFrame int // このフレームを経過しないとクリックできないようにする
@@ -33,12 +56,9 @@ func (g *gameclear) OnClick(x, y int) bool {
 	}
 
 	// ゲームリセットする
+	// このシーンも一緒に取り除かれる
 	g.game.Reset()
 
-	// ゲームオーバー画面を削除
-	g.game.clickHandler.Remove(g)
-	g.game.drawHandler.Remove(g)
-
 	return false
 }
 
@@ -58,6 +78,8 @@ func (g *gameclear) Draw(screen *ebiten.Image) {
 
 	// 画面中央に勝った感のあるメッセージを出す
 	drawText(screen, "Congratulations! All waves over!", screenWidth/2-490, eScreenHeight/2-100, 5, 5, color.RGBA{0xff, 0xff, 0xff, 0xff})
+	// このゲームの記録を出す
+	drawText(screen, g.game.stats.summary(), screenWidth/2-420, eScreenHeight/2, 2.5, 2.5, color.RGBA{0xff, 0xff, 0xff, 0xff})
 	// Click to Restart って出す
 	drawText(screen, "Click to Restart", screenWidth/2-230, eScreenHeight/2+100, 5, 5, color.RGBA{0xff, 0xff, 0xff, 0xff})
 }
@@ -1,12 +1,36 @@
 package main
 
 import (
+	"fmt"
 	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
+// ゲームオーバー画面のシーン
+// ゲーム本編の上に積まれる。後ろではゲーム本編が動き続ける
+type gameoverScene struct {
+	*handlerSet
+}
+
+func newGameoverScene(g *Game) *gameoverScene {
+	s := &gameoverScene{
+		handlerSet: newHandlerSet(),
+	}
+
+	o := newGameover(g)
+	s.updateHandler.Add(o)
+	s.clickHandler.Add(o)
+	s.drawHandler.Add(o)
+
+	return s
+}
+
+func (s *gameoverScene) UpdatesBelow() bool {
+	return true
+}
+
 type gameover struct {
 	game *Game
 
@@ -33,12 +57,9 @@ func (g *gameover) OnClick(x, y int) bool {
 	}
 
 	// ゲームリセットする
+	// このシーンも一緒に取り除かれる
 	g.game.Reset()
 
-	// ゲームオーバー画面を削除
-	g.game.clickHandler.Remove(g)
-	g.game.drawHandler.Remove(g)
-
 	return false
 }
 
@@ -59,6 +80,12 @@ func (g *gameover) Draw(screen *ebiten.Image) {
 
 	// 画面中央に負けた感のあるメッセージを出す
 	drawText(screen, "You lose! House destroyed...", screenWidth/2-400, eScreenHeight/2-100, 5, 5, color.RGBA{0xff, 0xff, 0xff, 0xff})
+	// このゲームの記録を出す
+	drawText(screen, g.game.stats.summary(), screenWidth/2-420, eScreenHeight/2, 2.5, 2.5, color.RGBA{0xff, 0xff, 0xff, 0xff})
+	// エンドレスモードでは乗り越えたウェーブの数がスコア
+	if g.game.world.Endless {
+		drawText(screen, fmt.Sprintf("Score: %d waves  Best: %d waves", g.game.world.Wave, g.game.bestWaves), screenWidth/2-420, eScreenHeight/2+40, 2.5, 2.5, color.RGBA{0xff, 0xff, 0xff, 0xff})
+	}
 	// Click to Restart って出す
 	drawText(screen, "Click to Restart", screenWidth/2-230, eScreenHeight/2+100, 5, 5, color.RGBA{0xff, 0xff, 0xff, 0xff})
 }
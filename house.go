@@ -10,6 +10,7 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/pankona/gj/sim"
 
 	_ "embed"
 	_ "image/png"
@@ -21,25 +22,25 @@ var houseImageData []byte
 type house struct {
 	game *Game
 
-	x, y          int // 画面中央に配置するので初期化時に値をもらう必要はない
+	// シミュレーション上の家
+	model *sim.Building
+
 	width, height int // 画像サイズをそのまま使うので初期化時に値をもらう必要はない
 	zindex        int // これも適当に調整するので初期化時に値をもらう必要はない
 	image         *ebiten.Image
 
-	health int
-
-	// 死亡時のアニメーションを管理するための変数
-	deadAnimationDuration int
-
 	// 画像の拡大率。
 	// TODO: 本当は画像のサイズそのものを変更したほうが見た目も処理効率も良くなる。余裕があれば後々やろう。
 	scale float64
 
-	// health が 0 になったときに呼ばれる関数
-	onDestroy func(h *house)
+	// 壊れたときにつぶれるアニメーション
+	collapse *Animation
 }
 
-func newHouse(game *Game) *house {
+// 家はシミュレーションの側で画面中央に配置されているので、その model を受け取る
+func newHouse(game *Game, model *sim.Building) *house {
+	loadAnimations()
+
 	img, _, err := image.Decode(bytes.NewReader(houseImageData))
 	if err != nil {
 		log.Fatal(err)
@@ -48,95 +49,69 @@ func newHouse(game *Game) *house {
 	h := &house{
 		game: game,
 
+		model: model,
+
 		width:  img.Bounds().Dx(),
 		height: img.Bounds().Dy(),
 		scale:  0.5,
 
-		health: 100,
-
 		image: ebiten.NewImageFromImage(img),
-
-		onDestroy: func(h *house) {
-			// TODO: 爆発したり消えたりする処理を書く
-			// ここでフラグを設定しといて、Update() や Draw で続きの処理を行うのもあり
-			// いったんシンプルに消す
-			h.game.updateHandler.Remove(h)
-			h.game.RemoveBuilding(h)
-			h.game.drawHandler.Remove(h)
-		},
 	}
 
-	h.x = screenWidth / 2
-	h.y = eScreenHeight / 2
-
 	return h
 }
 
-func (h *house) Update() {
-	if h.health <= 0 {
-		h.deadAnimationDuration++
-		if h.deadAnimationDuration >= deadAnimationTotalFrame {
-			h.onDestroy(h)
-		}
-		return
-	}
-}
-
 // 画面中央に配置
 func (h *house) Draw(screen *ebiten.Image) {
+	m := h.model
+
 	// 画像を描画
 	opts := &ebiten.DrawImageOptions{}
-	if h.health <= 0 {
+	img := h.image
+	if h.collapse != nil {
 		// 死亡時のアニメーションを行う
-		// ぺちゃんこになるように縮小する
-		// TODO: ちょっとアニメーションが怪しいので調整する
-		scale := h.scale * (1.0 - float64(h.deadAnimationDuration)/deadAnimationTotalFrame)
-		if scale < 0 {
-			scale = 0
-		}
-
-		opts.GeoM.Translate(0, h.scale*float64(-h.height)/2)
-		opts.GeoM.Scale(h.scale, scale)
-		opts.GeoM.Translate(0, h.scale*float64(h.height)/2)
-	} else {
-		opts.GeoM.Scale(h.scale, h.scale)
+		img = h.collapse.Frame()
 	}
 
-	opts.GeoM.Translate(float64(h.x)-float64(h.width)*h.scale/2, float64(h.y)-float64(h.height)*h.scale/2)
-	screen.DrawImage(h.image, opts)
+	opts.GeoM.Scale(h.scale, h.scale)
+	opts.GeoM.Translate(float64(m.X)-float64(h.width)*h.scale/2, float64(m.Y)-float64(h.height)*h.scale/2)
+	screen.DrawImage(img, opts)
+}
+
+// 壊れたらつぶれるアニメーションを始める
+func (h *house) Update() {
+	if h.collapse == nil {
+		if h.model.Health > 0 {
+			return
+		}
+		h.collapse = newAnimation(buildingCollapseClips[h.model.Kind], nil)
+	}
+	h.collapse.Update()
 }
 
 func (h *house) ZIndex() int {
 	return h.zindex
 }
 
+func (h *house) Model() *sim.Building {
+	return h.model
+}
+
 func (h *house) Position() (int, int) {
 	// 中央の座標を返す
-	return h.x, h.y
+	return h.model.Position()
 }
 
 func (h *house) SetPosition(x, y int) {
-	h.x = x
-	h.y = y
+	h.model.SetPosition(x, y)
 }
 
 func (h *house) Size() (int, int) {
-	return int(float64(h.width) * h.scale), int(float64(h.height) * h.scale)
+	return h.model.Size()
 }
 
 func (h *house) Name() string {
-	return "House"
-}
-
-func (h *house) Damage(d int) {
-	if h.health <= 0 {
-		return
-	}
-
-	h.health -= d
-	if h.health <= 0 {
-		h.health = 0
-	}
+	return h.model.Name()
 }
 
 // house implements Clickable interface
@@ -168,7 +143,7 @@ func (h *house) OnClick(x, y int) bool {
 		buildBarricadeButton := newButton(h.game,
 			225, eScreenHeight, infoPanelHeight, infoPanelHeight, 1,
 			func(x, y int) bool {
-				if h.game.credit < CostBarricadeBuild {
+				if h.game.world.Credit < sim.BuildingStatsOf(sim.BuildingBarricade).Cost {
 					// お金が足りない場合は建築できない
 					return false
 				}
@@ -178,17 +153,10 @@ func (h *house) OnClick(x, y int) bool {
 				// buildCandidate を持っているときにバリケードボタンを押したときの振る舞い
 				// 選択肢なおしということ、いったん手放す
 				if h.game.buildCandidate != nil {
-					h.game.drawHandler.Remove(h.game.buildCandidate)
-				}
-
-				barricadeOnDestroyFn := func(b *barricade) {
-					b.game.drawHandler.Remove(b)
-					b.game.clickHandler.Remove(b)
-					b.game.RemoveBuilding(b)
-					b.game.infoPanel.Remove(b)
+					h.game.phaseHandlers.drawHandler.Remove(h.game.buildCandidate)
 				}
 
-				b := newBarricade(h.game, 0, 0, barricadeOnDestroyFn)
+				b := newBarricade(h.game, 0, 0)
 				h.game.buildCandidate = b
 				h.game.infoPanel.drawDescriptionFn = func(screen *ebiten.Image, x, y int) {
 					x = x - 150
@@ -206,7 +174,7 @@ func (h *house) OnClick(x, y int) bool {
 				barricadeIcon := newBarricadeIcon(x+width/2, y+height/2-10)
 				barricadeIcon.Draw(screen)
 
-				ebitenutil.DebugPrintAt(screen, fmt.Sprintf("BUILD ($%d)", CostBarricadeBuild), x+width/2-30, y+height/2+40)
+				ebitenutil.DebugPrintAt(screen, fmt.Sprintf("BUILD ($%d)", sim.BuildingStatsOf(sim.BuildingBarricade).Cost), x+width/2-30, y+height/2+40)
 
 				// 選択中であればボタンをハイライト表示する
 				if h.game.buildCandidate != nil && h.game.buildCandidate.Name() == "Barricade" {
@@ -214,7 +182,7 @@ func (h *house) OnClick(x, y int) bool {
 				}
 
 				// お金が足りないときはボタン全体をグレーアウトする
-				if h.game.credit < CostBarricadeBuild {
+				if h.game.world.Credit < sim.BuildingStatsOf(sim.BuildingBarricade).Cost {
 					overlay := ebiten.NewImage(width, height)
 					overlay.Fill(color.RGBA{128, 128, 128, 128})
 					overlayOpts := &ebiten.DrawImageOptions{}
@@ -228,7 +196,7 @@ func (h *house) OnClick(x, y int) bool {
 		buildTowerButton := newButton(h.game,
 			225+infoPanelHeight, eScreenHeight, infoPanelHeight, infoPanelHeight, 1,
 			func(x, y int) bool {
-				if h.game.credit < CostTowerBuild {
+				if h.game.world.Credit < sim.BuildingStatsOf(sim.BuildingTower).Cost {
 					// お金が足りない場合は建築できない
 					return false
 				}
@@ -238,18 +206,10 @@ func (h *house) OnClick(x, y int) bool {
 				// buildCandidate を持っているときにバリケードボタンを押したときの振る舞い
 				// 選択肢なおしということ、いったん手放す
 				if h.game.buildCandidate != nil {
-					h.game.drawHandler.Remove(h.game.buildCandidate)
-				}
-
-				towerOnDestroyFn := func(b *tower) {
-					b.game.updateHandler.Remove(b)
-					b.game.drawHandler.Remove(b)
-					b.game.clickHandler.Remove(b)
-					b.game.RemoveBuilding(b)
-					b.game.infoPanel.Remove(b)
+					h.game.phaseHandlers.drawHandler.Remove(h.game.buildCandidate)
 				}
 
-				t := newTower(h.game, 0, 0, towerOnDestroyFn)
+				t := newTower(h.game, 0, 0)
 				h.game.buildCandidate = t
 				t.game.infoPanel.drawDescriptionFn = func(screen *ebiten.Image, x, y int) {
 					x = x - 150
@@ -267,7 +227,7 @@ func (h *house) OnClick(x, y int) bool {
 				towerIcon := newTowerIcon(x+width/2, y+height/2-10)
 				towerIcon.Draw(screen)
 
-				ebitenutil.DebugPrintAt(screen, fmt.Sprintf("BUILD ($%d)", CostTowerBuild), x+width/2-30, y+height/2+40)
+				ebitenutil.DebugPrintAt(screen, fmt.Sprintf("BUILD ($%d)", sim.BuildingStatsOf(sim.BuildingTower).Cost), x+width/2-30, y+height/2+40)
 
 				// 選択中であればボタンをハイライト表示する
 				if h.game.buildCandidate != nil && h.game.buildCandidate.Name() == "Tower" {
@@ -275,7 +235,7 @@ func (h *house) OnClick(x, y int) bool {
 				}
 
 				// お金が足りないときはボタン全体をグレーアウトする
-				if h.game.credit < CostTowerBuild {
+				if h.game.world.Credit < sim.BuildingStatsOf(sim.BuildingTower).Cost {
 					overlay := ebiten.NewImage(width, height)
 					overlay.Fill(color.RGBA{128, 128, 128, 128})
 					overlayOpts := &ebiten.DrawImageOptions{}
@@ -288,7 +248,7 @@ func (h *house) OnClick(x, y int) bool {
 		buildRadioTowerButton := newButton(h.game,
 			225+infoPanelHeight*2, eScreenHeight, infoPanelHeight, infoPanelHeight, 1,
 			func(x, y int) bool {
-				if h.game.credit < CostRadioTowerBuild {
+				if h.game.world.Credit < sim.BuildingStatsOf(sim.BuildingRadioTower).Cost {
 					// お金が足りない場合は建築できない
 					return false
 				}
@@ -298,18 +258,10 @@ func (h *house) OnClick(x, y int) bool {
 				// buildCandidate を持っているときにバリケードボタンを押したときの振る舞い
 				// 選択肢なおしということ、いったん手放す
 				if h.game.buildCandidate != nil {
-					h.game.drawHandler.Remove(h.game.buildCandidate)
+					h.game.phaseHandlers.drawHandler.Remove(h.game.buildCandidate)
 				}
 
-				radioTowerOnDestroyFn := func(b *radioTower) {
-					b.game.updateHandler.Remove(b)
-					b.game.drawHandler.Remove(b)
-					b.game.clickHandler.Remove(b)
-					b.game.RemoveBuilding(b)
-					b.game.infoPanel.Remove(b)
-				}
-
-				rt := newRadioTower(h.game, 0, 0, radioTowerOnDestroyFn)
+				rt := newRadioTower(h.game, 0, 0)
 				h.game.buildCandidate = rt
 				h.game.infoPanel.drawDescriptionFn = func(screen *ebiten.Image, x, y int) {
 					x = x - 150
@@ -327,7 +279,7 @@ func (h *house) OnClick(x, y int) bool {
 				radioTowerIcon := newRadioTowerIcon(x+width/2, y+height/2-10)
 				radioTowerIcon.Draw(screen)
 
-				ebitenutil.DebugPrintAt(screen, fmt.Sprintf("BUILD ($%d)", CostRadioTowerBuild), x+width/2-30, y+height/2+40)
+				ebitenutil.DebugPrintAt(screen, fmt.Sprintf("BUILD ($%d)", sim.BuildingStatsOf(sim.BuildingRadioTower).Cost), x+width/2-30, y+height/2+40)
 
 				// 選択中であればボタンをハイライト表示する
 				if h.game.buildCandidate != nil && h.game.buildCandidate.Name() == "RadioTower" {
@@ -335,7 +287,7 @@ func (h *house) OnClick(x, y int) bool {
 				}
 
 				// お金が足りないときはボタン全体をグレーアウトする
-				if h.game.credit < CostRadioTowerBuild {
+				if h.game.world.Credit < sim.BuildingStatsOf(sim.BuildingRadioTower).Cost {
 					overlay := ebiten.NewImage(width, height)
 					overlay.Fill(color.RGBA{128, 128, 128, 128})
 					overlayOpts := &ebiten.DrawImageOptions{}
@@ -353,7 +305,7 @@ func (h *house) OnClick(x, y int) bool {
 				switch h.game.phase {
 				case PhaseBuilding:
 					// 最初のウェーブだったら攻撃方法に関する説明を表示する
-					if h.game.waveCtrl.currentBigWave == 0 {
+					if h.game.world.Wave == 0 {
 						h.game.attackInstruction = newInstruction(h.game, "CLICK BUGS TO ATTACK!", screenWidth/2-60, eScreenHeight/2+50)
 						h.game.drawHandler.Add(h.game.attackInstruction)
 					}
@@ -372,7 +324,12 @@ func (h *house) OnClick(x, y int) bool {
 				drawText(screen, "FINISH BUILDING!", x+width/2-45, y+height/2-40, 1, 1, color.RGBA{0xff, 0xff, 0xff, 0xff})
 				drawText(screen, "START NEXT WAVE!", x+width/2-45, y+height/2-8, 1, 1, color.RGBA{0xff, 0xff, 0xff, 0xff})
 				// 現在のウェーブとトータルウェーブ数を表示する
-				drawText(screen, fmt.Sprintf("CURRENT WAVE: %d/%d", h.game.waveCtrl.currentBigWave+1, len(waveList)), x+width/2-52, y+height/2+32, 1, 1, color.RGBA{0xff, 0xff, 0xff, 0xff})
+				// エンドレスモードではトータルがないので現在のウェーブだけ
+				wave := fmt.Sprintf("CURRENT WAVE: %d/%d", h.game.world.Wave+1, h.game.world.WaveCount())
+				if h.game.world.Endless {
+					wave = fmt.Sprintf("CURRENT WAVE: %d", h.game.world.Wave+1)
+				}
+				drawText(screen, wave, x+width/2-52, y+height/2+32, 1, 1, color.RGBA{0xff, 0xff, 0xff, 0xff})
 			},
 		)
 		h.game.infoPanel.AddButton(nextWaveStartButton)
@@ -396,7 +353,7 @@ func drawYellowRect(screen *ebiten.Image, x, y, width, height int) {
 }
 
 func (h *house) Health() int {
-	return h.health
+	return h.model.Health
 }
 
 // グレーアウトした drawRect を描画
@@ -417,8 +374,9 @@ func drawRect(screen *ebiten.Image, x, y, width, height int) {
 }
 
 func (h *house) IsClicked(x, y int) bool {
+	hx, hy := h.Position()
 	width, height := h.Size()
-	return h.x-width/2 <= x && x <= h.x+width/2 && h.y-height/2 <= y && y <= h.y+height/2
+	return hx-width/2 <= x && x <= hx+width/2 && hy-height/2 <= y && y <= hy+height/2
 }
 
 func (h *house) SetOverlap(overlap bool) {
@@ -431,6 +389,5 @@ func (h *house) IsOverlap() bool {
 }
 
 func (h *house) Cost() int {
-	// 登場の機会はないので実装しない
-	return 0
+	return h.model.Cost()
 }
@@ -6,6 +6,7 @@ import (
 	"log"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/pankona/gj/sim"
 )
 
 type icon struct {
@@ -69,26 +70,13 @@ func newBarricadeIcon(x, y int) *icon {
 	return newIcon(x, y, ebiten.NewImageFromImage(img))
 }
 
-func newBugIcon(x, y int, bugColor bugColor) *icon {
+func newBugIcon(x, y int, kind sim.BugKind) *icon {
 	img, _, err := image.Decode(bytes.NewReader(bugsImageData))
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	bugsImage := ebiten.NewImageFromImage(img)
-	rect := func() image.Rectangle {
-		switch bugColor {
-		case bugsRed:
-			return redBug()
-		case bugsBlue:
-			return blueBug()
-		case bugsGreen:
-			return greenBug()
-		}
-		log.Fatal("invalid bug color")
-		return image.Rectangle{}
-	}()
-	bugImage := bugsImage.SubImage(rect).(*ebiten.Image)
 
-	return newIcon(x, y, bugImage)
+	return newIcon(x, y, bugImage(bugsImage, kind))
 }
@@ -1,13 +1,16 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"image/color"
 	"log"
+	"os"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/pankona/gj/sim"
 )
 
 type Game struct {
@@ -15,22 +18,68 @@ type Game struct {
 	clickedPositionX, clickedPositionY int
 	clickedObject                      string
 
-	// ゲーム全編通して使うハンドラ
+	// シーンのスタック
+	scenes *sceneManager
+
+	// マウスやタッチの状態
+	reader  inputReader
+	pointer pointerTracker
+
+	// ゲームのシード
+	// seedFixed が true のときはリセットしても同じシードで始める
+	seed      int64
+	seedFixed bool
+
+	// このフレームの入力
+	input frameInput
+
+	// 入力の記録と再生
+	// player が nil でなければ、デバイスのかわりに記録された入力を使う
+	recorder   *inputRecorder
+	player     *inputPlayer
+	replayPath string
+
+	// エンドレスモードかどうかと、その最高記録 (乗り越えたウェーブの数)
+	endless   bool
+	bestWaves int
+
+	// 難易度
+	difficulty sim.Difficulty
+
+	// セーブデータから再開するときの状態
+	// nil でなければ initialize でここから World を作る
+	resume *sim.Snapshot
+
+	// -stats で能力値を上書きしたときは、そのファイルのハッシュ
+	// リプレイに記録して、再生するときに同じかどうか確かめる
+	statsHash string
+
+	// ゲーム本編のシーンのハンドラ
+	// playScene に入るときに差し替えられる
 	clickHandler  *OnClickHandler
 	drawHandler   *DrawHandler
 	updateHandler *UpdateHandler
 
+	// 現在のフェーズの間だけ有効なハンドラ
+	// フェーズが切り替わるとまるごと捨てられる
+	phaseHandlers *handlerSet
+
 	// 以下はメインのゲームシーンで使う変数
-	// TODO: シーンごとに Game 構造体を分けるべきかもしれない
+	// TODO: playScene に持たせるべきかもしれない
 	phase Phase
 
+	// ウェーブと戦闘のシミュレーション
+	// 以下の house, entities はこの中にいるものを描画したりクリックしたりするためのもの
+	world *sim.World
+
 	house *house
 
-	// 建物のリスト
-	buildings []Building
+	// 建物と敵
+	// シミュレーション上の ID で引ける
+	entities *entityRegistry
 
-	// 敵のリスト
-	enemies []Enemy
+	// このゲームの記録
+	stats *gameStats
 
 	// 情報パネル
 	infoPanel *infoPanel
@@ -45,20 +94,21 @@ type Game struct {
 	// ウェーブのコントローラ
 	waveCtrl *waveController
 
+	// 一時停止と早送り
+	speedCtrl *speedControl
+
 	// 最初のウェーブが始まる前に表示するインストラクション
 	buildInstruction *instruction
 
 	// 攻撃のインストラクション
 	attackInstruction *instruction
-
-	credit int
 }
 
 type Phase int
 
 const (
-	screenWidth  = 1280
-	screenHeight = 960
+	screenWidth  = sim.ScreenWidth
+	screenHeight = sim.ScreenHeight
 )
 
 const (
@@ -73,43 +123,50 @@ const (
 	PhaseWave
 )
 
-// コスト一覧
-const (
-	CostBarricadeBuild  = 50
-	CostTowerBuild      = 150
-	CostRadioTowerBuild = 250
-)
-
 const (
 	// infoPanel の高さを計算
 	// infoPanel の高さの分だけ、ゲーム画面の中央座標が上にずれる
 	// 中央座標計算のためにあらかじめここで計算しておく
 	infoPanelHeight = screenHeight / 7
-	eScreenHeight   = screenHeight - infoPanelHeight - 10
+	eScreenHeight   = sim.FieldHeight
 )
 
 func (g *Game) Update() error {
+	// このフレームの入力を決める
+	// 入力はすべてここを通るので、記録しておけば同じゲームを再生できる
+	in := g.nextInput()
+	g.input = in
+
+	// ホバーやドラッグなどのポインタのイベントを一番上のシーンに渡す
+	// クリックより先に渡すので、クリックされたときにはカーソルの位置にあわせた状態になっている
+	for _, e := range g.pointer.Update(in.pointer) {
+		g.scenes.HandlePointer(e)
+	}
 
-	// getClickPosition の戻り値を clickHandler.HandleClick に渡す
+	// getClickPosition の戻り値を一番上のシーンに渡す
 	// これをやると登録された Clickable の OnClick が呼ばれる
-	if x, y, clicked := getClickedPosition(); clicked {
-		g.clickHandler.HandleClick(x, y)
+	if in.clicked {
+		g.scenes.HandleClick(in.clickX, in.clickY)
 	}
 
-	// Updater を実行
-	g.updateHandler.HandleUpdate()
+	// シーンを更新する
+	g.scenes.Update()
 
-	x, y, clicked := getClickedPosition()
-	if clicked {
-		g.clickedPositionX = x
-		g.clickedPositionY = y
+	if in.clicked {
+		g.clickedPositionX = in.clickX
+		g.clickedPositionY = in.clickY
 	}
 
 	return nil
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	g.drawHandler.HandleDraw(screen)
+	g.scenes.Draw(screen)
+
+	// 再生中であることがわかるようにしておく
+	if g.player != nil {
+		ebitenutil.DebugPrintAt(screen, "REPLAY", screenWidth/2-20, 0)
+	}
 
 	// 以下はデバッグ情報
 	if debugEnabled {
@@ -135,7 +192,7 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		// 画面中央に点を表示 (debug)
 		vector.DrawFilledRect(screen, screenWidth/2, eScreenHeight/2, 1, 1, color.RGBA{255, 255, 255, 255}, true)
 		// 残りの敵の数を表示
-		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Enemies: %d", len(g.enemies)), 0, 120)
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Enemies: %d", len(g.world.Bugs)), 0, 120)
 
 		// FPS を表示
 		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("FPS: %0.2f", ebiten.ActualFPS()), 0, 140)
@@ -150,7 +207,7 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Active Audio: %d", activeAudioNum), 0, 160)
 
 		// 画面右上にクレジットを表示
-		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Credit: %d", g.credit), screenWidth-100, 0)
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Credit: %d", g.world.Credit), screenWidth-100, 0)
 	}
 }
 
@@ -165,13 +222,14 @@ func (g *Game) SetBuildingPhase() {
 	g.infoPanel.unit = nil
 	g.infoPanel.ClearButtons()
 
-	// wave phase で追加したものを削除
-	g.attackPane.RemoveAll()
+	// wave phase で追加したものはハンドラごと捨てる
+	g.phaseHandlers = newHandlerSet()
+	g.attackPane = nil
 
 	// Build phase に必要なものを追加
 	g.buildPane = newBuildPane(g)
-	g.clickHandler.Add(g.buildPane)
-	g.drawHandler.Add(g.buildPane)
+	g.phaseHandlers.clickHandler.Add(g.buildPane)
+	g.phaseHandlers.drawHandler.Add(g.buildPane)
 }
 
 func (g *Game) SetWavePhase() {
@@ -181,34 +239,50 @@ func (g *Game) SetWavePhase() {
 	g.infoPanel.unit = nil
 	g.infoPanel.ClearButtons()
 
-	// building phase で追加したものを削除
-	g.buildPane.RemoveAll()
+	// building phase で追加したものはハンドラごと捨てる
+	// 建築するつもりで持っているもの (の描画) もここで捨てられる
+	g.phaseHandlers = newHandlerSet()
+	g.buildPane = nil
 
 	// 建築するつもりで持っているものも手放してもらう
 	// これをやっとかないと次の建築フェーズで開幕から建築物を持っている状態になってしまう
-	if g.buildCandidate != nil {
-		g.drawHandler.Remove(g.buildCandidate)
-		g.buildCandidate = nil
-	}
+	g.buildCandidate = nil
 
 	// Wave phase に必要なものを追加
 	g.attackPane = newAttackPane(g)
-	g.clickHandler.Add(g.attackPane)
+	g.phaseHandlers.clickHandler.Add(g.attackPane)
+	g.phaseHandlers.drawHandler.Add(newSmallHand(g))
 
-	g.updateHandler.Add(g.waveCtrl)
+	g.world.StartWave()
+	g.phaseHandlers.updateHandler.Add(g.waveCtrl)
+
+	// 一時停止と早送りのボタンを出す
+	// 倍率は前のウェーブのものを引き継ぐ
+	g.speedCtrl.paused = false
+	g.speedCtrl.addButtons(g.phaseHandlers)
 }
 
 func (g *Game) initialize() {
-	// とりあえずいきなりゲームが始まるとする。
-	// TODO: まずタイトルバックを表示して、その後にゲーム画面に遷移するようにする
 	aplayer := getAudioPlayer()
 	aplayer.playBGM()
 
-	g.house = newHouse(g)
-	g.updateHandler.Add(g.house)
-	g.drawHandler.Add(g.house)
-	g.AddBuilding(g.house)
-	g.clickHandler.Add(g.house)
+	if g.resume != nil {
+		g.restoreWorld()
+	} else {
+		g.world = sim.NewWorld(g.seed)
+		g.world.Endless = g.endless
+		g.world.SetDifficulty(g.difficulty)
+	}
+	g.stats = &gameStats{}
+	g.subscribeWorldEvents(g.world.Events)
+	subscribeSound(g.world.Events)
+	g.subscribeEffects(g.world.Events)
+	g.subscribeBoss(g.world.Events)
+	g.stats.subscribe(g.world.Events)
+	newAchievementTracker(g, g.stats).subscribe(g.world.Events)
+
+	g.house = newHouse(g, g.world.House)
+	g.entities.Register(g.world.House.ID, g.house)
 
 	g.infoPanel = newInfoPanel(g, screenWidth-20, infoPanelHeight)
 	g.drawHandler.Add(g.infoPanel)
@@ -217,12 +291,35 @@ func (g *Game) initialize() {
 	bg := newBackground(g)
 	g.drawHandler.Add(bg)
 
-	// クレジットを初期化
-	g.credit = 100
+	// インストラクションを表示
+	// 家がクリックされたら消える
+	g.buildInstruction = newInstruction(g, "CLICK ME TO OPEN BUILD MENU", screenWidth/2-80, eScreenHeight/2+50)
+	g.drawHandler.Add(g.buildInstruction)
+
+	g.waveCtrl = newWaveController(g)
+	g.speedCtrl = newSpeedControl(g)
+
+	// 新しいゲームの入力を記録しはじめる
+	// ここは建築フェーズなのでシミュレーションは止まっている
+	// 残りのフレームで何が起きても次のフレームから記録すれば同じゲームになる
+	g.startRecording()
+}
 
+// シミュレーションのイベントに応じて、描画用のオブジェクトを出し入れしたりフェーズを切り替えたりする
+// 音やエフェクト、統計は別の購読者が担当する
+func (g *Game) subscribeWorldEvents(bus *sim.EventBus) {
+	sim.Subscribe(bus, func(e sim.BugSpawned) {
+		g.entities.Register(e.Bug.ID, newBug(g, e.Bug))
+	})
+	sim.Subscribe(bus, func(e sim.BugRemoved) {
+		g.entities.Remove(e.Bug.ID)
+	})
+	sim.Subscribe(bus, func(e sim.BuildingRemoved) {
+		// TODO: 家が壊れたときに爆発したり消えたりする処理を書く
+		g.entities.Remove(e.Building.ID)
+	})
 	// 敵が全滅したらウェーブを終了して建築フェーズに戻る
-	// 敵が全滅したことをコールバックする
-	waveEndFn := func() {
+	sim.Subscribe(bus, func(e sim.WaveCleared) {
 		// 最初のウェーブが終了したら攻撃インストラクションを消す
 		if g.attackInstruction != nil {
 			g.drawHandler.Remove(g.attackInstruction)
@@ -233,51 +330,110 @@ func (g *Game) initialize() {
 		g.buildInstruction = newInstruction(g, "CLICK ME TO OPEN BUILD MENU", screenWidth/2-80, eScreenHeight/2+50)
 		g.drawHandler.Add(g.buildInstruction)
 
-		// TODO: ウェーブが終わっておめでとう的なことを少しの間だけ表示する
-		// その後に建築フェーズに移行する
-
 		g.SetBuildingPhase()
-		// ウェーブ終了時に一定のクレジットを得る
-		g.credit += 120
-	}
 
-	// インストラクションを表示
-	// 家がクリックされたら消える
-	g.buildInstruction = newInstruction(g, "CLICK ME TO OPEN BUILD MENU", screenWidth/2-80, eScreenHeight/2+50)
-	g.drawHandler.Add(g.buildInstruction)
+		// エンドレスモードでは乗り越えたウェーブの数がスコアになる
+		if g.world.Endless {
+			g.updateBestScore(g.world.Wave)
+		}
 
-	g.waveCtrl = newWaveController(g, waveEndFn)
+		// ウェーブ間の処理
+		if g.world.Endless || g.world.Wave < g.world.WaveCount() {
+			// 建築フェーズの始まりなので、ここまでの状態をセーブしておく
+			g.saveProgress()
+
+			t := newTimerText(g, screenWidth/2-350, screenHeight/2+50, fmt.Sprintf("Wave Clear! Credit Earned! $%d", g.world.Difficulty.WaveClearReward))
+			g.drawHandler.Add(t)
+			g.updateHandler.Add(t)
+			remaining := fmt.Sprintf("Waves remaining: %d", g.world.WaveCount()-g.world.Wave)
+			if g.world.Endless {
+				remaining = fmt.Sprintf("Waves cleared: %d (Best: %d)", g.world.Wave, g.bestWaves)
+			}
+			t = newTimerText(g, screenWidth/2-200, screenHeight/2+150, remaining)
+			g.drawHandler.Add(t)
+			g.updateHandler.Add(t)
+		}
+	})
+	// ゲームクリアの処理
+	sim.Subscribe(bus, func(e sim.AllCleared) {
+		g.saveReplay()
+		g.clearProgress()
+		g.scenes.Push(newGameClearScene(g))
+	})
+	// ゲームオーバーの処理
+	// 後ろではシミュレーションが動き続ける
+	sim.Subscribe(bus, func(e sim.GameOver) {
+		g.saveReplay()
+		g.clearProgress()
+		g.scenes.Push(newGameoverScene(g))
+	})
 }
 
+// Reset は積まれているシーンをすべて捨てて、新しいゲーム本編のシーンを始める
+// シードを決めていなければ新しいシードになり、ウェーブの中身も変わる
 func (g *Game) Reset() {
-	g.clickHandler.Clear()
-	g.drawHandler.Clear()
-	g.updateHandler.Clear()
-	g.buildings = []Building{}
-	g.enemies = []Enemy{}
-
-	g.initialize()
-	g.SetBuildingPhase()
+	if !g.seedFixed {
+		g.seed = newSeed()
+	}
+	g.Restart()
+}
+
+// Restart は積まれているシーンをすべて捨てて、いまのシードでゲーム本編のシーンを始めなおす
+// タイトルに出ていたシードのまま遊べるように、新しいシードは引かない
+func (g *Game) Restart() {
+	g.resume = nil
+	g.scenes.Switch(newPlayScene(g))
 }
 
 func main() {
 	ebiten.SetWindowSize(screenWidth, screenHeight)
 	ebiten.SetWindowTitle("House Defence Operation")
 
-	g := &Game{
-		clickHandler:  &OnClickHandler{},
-		drawHandler:   &DrawHandler{},
-		updateHandler: &UpdateHandler{},
+	seed := flag.Int64("seed", -1, "seed for the game (random if negative)")
+	record := flag.String("record", defaultReplayPath, "file to save the replay to when a game ends (disabled if empty)")
+	replay := flag.String("replay", "", "replay file to play back")
+	statsFile := flag.String("stats", "", "file to override the unit and building stats")
+	flag.Parse()
+
+	// 能力値を上書きする
+	// 虫や建物を作る前に読んでおく
+	var stats string
+	if *statsFile != "" {
+		b, err := os.ReadFile(*statsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := sim.LoadStats(b); err != nil {
+			log.Fatalf("invalid stats file: %v", err)
+		}
+		stats = statsHash(b)
 	}
 
-	g.initialize()
-
-	// 最初のシーンをセットアップする
-	g.SetBuildingPhase()
+	g := &Game{
+		scenes:     &sceneManager{},
+		seed:       newSeed(),
+		replayPath: *record,
+		bestWaves:  loadBestScore(),
+		difficulty: sim.DifficultyNormal,
+		statsHash:  stats,
+	}
+	if *seed >= 0 {
+		g.seed = *seed
+		g.seedFixed = true
+	}
 
-	title := newTitle(g)
-	g.drawHandler.Add(title)
-	g.clickHandler.Add(title)
+	if *replay != "" {
+		data, err := loadReplay(*replay, stats)
+		if err != nil {
+			log.Fatal(err)
+		}
+		g.startPlayback(data)
+	} else {
+		// ゲーム本編の上にタイトルを積んでおく
+		// タイトルが取り除かれるとゲーム本編が始まる
+		g.scenes.Push(newPlayScene(g))
+		g.scenes.Push(newTitleScene(g))
+	}
 
 	if err := ebiten.RunGame(g); err != nil {
 		log.Fatal(err)
@@ -7,6 +7,7 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/pankona/gj/sim"
 )
 
 // パネルの枠を表示するための構造体
@@ -96,6 +97,14 @@ func (p *infoPanel) Draw(screen *ebiten.Image) {
 
 	// TODO: アイコンを描画
 
+	// 右下にシードを表示する
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("SEED: %d", p.game.world.Seed), p.x+p.width-120, p.y+p.height-20)
+	// 難易度と、エンドレスモードでは最高記録も表示する
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("DIFFICULTY: %s", p.game.world.Difficulty.Name), p.x+p.width-120, p.y+p.height-40)
+	if p.game.world.Endless {
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("ENDLESS BEST: %d", p.game.bestWaves), p.x+p.width-120, p.y+p.height-60)
+	}
+
 	// ユニット名とHPを描画
 	if p.unit == nil {
 		return
@@ -107,7 +116,12 @@ func (p *infoPanel) Draw(screen *ebiten.Image) {
 
 	// 家だったら現在のクレジットも表示する
 	if name == "House" {
-		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("$: %d", p.game.credit), p.x+100+40, p.y+70)
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("$: %d", p.game.world.Credit), p.x+100+40, p.y+70)
+	}
+
+	// 攻撃力などの能力値を表示する
+	for i, line := range statLines(p.unit) {
+		ebitenutil.DebugPrintAt(screen, line, p.x+100+40, p.y+70+20*i)
 	}
 
 	// ボタンを描画
@@ -122,6 +136,28 @@ func (p *infoPanel) Draw(screen *ebiten.Image) {
 	}
 }
 
+// statLines は u の能力値を表示するための文字列を返す
+// 値はシミュレーション上の虫や建物 (stats.json から作られたもの) から取り出す
+func statLines(u infoer) []string {
+	switch u := u.(type) {
+	case interface{ Model() *sim.Bug }:
+		m := u.Model()
+		return []string{
+			fmt.Sprintf("ATK: %d  RANGE: %.0f", m.AttackPower, m.AttackRange),
+			fmt.Sprintf("SPEED: %.0f", m.Speed),
+		}
+	case interface{ Model() *sim.Building }:
+		m := u.Model()
+		switch m.Kind {
+		case sim.BuildingTower:
+			return []string{fmt.Sprintf("ATK: %d  RANGE: %.0f", m.AttackPower, m.AttackRange)}
+		case sim.BuildingRadioTower:
+			return []string{fmt.Sprintf("ATK: %d  RANGE: %.0f-%.0f", m.AttackPower, m.ShortAttackRange, m.LongAttackRange)}
+		}
+	}
+	return nil
+}
+
 func (p *infoPanel) ZIndex() int {
 	return p.zindex
 }
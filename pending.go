@@ -0,0 +1,56 @@
+package main
+
+// ハンドラが登録されたものを呼び出している最中に Add/Remove されたものをためておくキュー
+// 呼び出しの最中にスライスを書き換えると、ループが次の要素を飛ばしてしまったりするため、
+// 呼び出しが終わってから (フレームの区切りで) まとめて反映する
+type pendingQueue[T comparable] struct {
+	ops []pendingOp[T]
+}
+
+type pendingOp[T comparable] struct {
+	obj    T
+	remove bool
+}
+
+func (q *pendingQueue[T]) add(obj T) {
+	q.ops = append(q.ops, pendingOp[T]{obj: obj})
+}
+
+func (q *pendingQueue[T]) remove(obj T) {
+	q.ops = append(q.ops, pendingOp[T]{obj: obj, remove: true})
+}
+
+// lookup は obj に対する最後の操作を返す
+// obj に対する操作がなければ ok が false になる
+func (q *pendingQueue[T]) lookup(obj T) (removed bool, ok bool) {
+	for i := len(q.ops) - 1; i >= 0; i-- {
+		if q.ops[i].obj == obj {
+			return q.ops[i].remove, true
+		}
+	}
+	return false, false
+}
+
+// isRemoved は obj が呼び出しの最中に取り除かれたかどうかを返す
+func (q *pendingQueue[T]) isRemoved(obj T) bool {
+	removed, ok := q.lookup(obj)
+	return ok && removed
+}
+
+// flush はためておいた操作を順番どおりに反映する
+func (q *pendingQueue[T]) flush(add, remove func(T)) {
+	ops := q.ops
+	q.ops = nil
+	for _, op := range ops {
+		if op.remove {
+			remove(op.obj)
+		} else {
+			add(op.obj)
+		}
+	}
+}
+
+// reset はためておいた操作をすべて捨てる
+func (q *pendingQueue[T]) reset() {
+	q.ops = nil
+}
@@ -22,3 +22,95 @@ func getClickedPosition() (x, y int, eventOccurred bool) {
 	// イベントが発生しなかった場合
 	return 0, 0, false
 }
+
+type pointerEventKind int
+
+const (
+	// カーソルが (x, y) にある。マウスのときだけ毎フレーム発生する
+	pointerHover pointerEventKind = iota
+	// カーソルがどこにも乗っていないことにする。乗っていたものすべてから離れる
+	pointerLeave
+	// 押された
+	pointerPress
+	// 離された
+	pointerRelease
+	// 押したまま動かし始めた。(x, y) は押した位置
+	pointerDragStart
+	// 押したまま動かしている
+	pointerDragMove
+	// ドラッグ中に離された
+	pointerDragEnd
+)
+
+type pointerEvent struct {
+	kind pointerEventKind
+	x, y int
+}
+
+// 押した位置からこれより動いたらドラッグとみなす
+const dragThreshold = 4
+
+// pointerTracker はマウスやタッチの状態をフレームごとに見て pointerEvent を作る
+type pointerTracker struct {
+	pressed        bool
+	pressX, pressY int
+	dragging       bool
+
+	// 最後に見た位置
+	// タッチは離したあとの位置がとれないので、離したときはこれを使う
+	lastX, lastY int
+}
+
+// Update はこのフレームのポインタの状態から pointerEvent を作る
+func (p *pointerTracker) Update(s pointerState) []pointerEvent {
+	return p.next(s.x, s.y, s.down, s.hover)
+}
+
+// next は (x, y) に押されているか (down) どうかを受け取ってイベントを作る
+// hover が false のときは hover イベントを作らない
+func (p *pointerTracker) next(x, y int, down, hover bool) []pointerEvent {
+	var events []pointerEvent
+
+	if hover {
+		events = append(events, pointerEvent{kind: pointerHover, x: x, y: y})
+	}
+
+	switch {
+	case down && !p.pressed:
+		p.pressed = true
+		p.pressX, p.pressY = x, y
+		events = append(events, pointerEvent{kind: pointerPress, x: x, y: y})
+
+	case down && p.pressed:
+		if !p.dragging {
+			dx, dy := x-p.pressX, y-p.pressY
+			if dx*dx+dy*dy <= dragThreshold*dragThreshold {
+				break
+			}
+			p.dragging = true
+			events = append(events, pointerEvent{kind: pointerDragStart, x: p.pressX, y: p.pressY})
+			events = append(events, pointerEvent{kind: pointerDragMove, x: x, y: y})
+		} else if x != p.lastX || y != p.lastY {
+			events = append(events, pointerEvent{kind: pointerDragMove, x: x, y: y})
+		}
+
+	case !down && p.pressed:
+		// マウスなら離した位置がわかる
+		rx, ry := p.lastX, p.lastY
+		if hover {
+			rx, ry = x, y
+		}
+		if p.dragging {
+			events = append(events, pointerEvent{kind: pointerDragEnd, x: rx, y: ry})
+		}
+		events = append(events, pointerEvent{kind: pointerRelease, x: rx, y: ry})
+		p.pressed = false
+		p.dragging = false
+	}
+
+	if down || hover {
+		p.lastX, p.lastY = x, y
+	}
+
+	return events
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+)
+
+func kinds(events []pointerEvent) []pointerEventKind {
+	var ret []pointerEventKind
+	for _, e := range events {
+		ret = append(ret, e.kind)
+	}
+	return ret
+}
+
+func equalKinds(a, b []pointerEventKind) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPointerTrackerClick(t *testing.T) {
+	p := &pointerTracker{}
+
+	if got := kinds(p.next(10, 10, false, true)); !equalKinds(got, []pointerEventKind{pointerHover}) {
+		t.Errorf("expected hover only, got %v", got)
+	}
+	if got := kinds(p.next(10, 10, true, true)); !equalKinds(got, []pointerEventKind{pointerHover, pointerPress}) {
+		t.Errorf("expected hover and press, got %v", got)
+	}
+	// しきい値以内の動きはドラッグにならない
+	if got := kinds(p.next(12, 10, true, true)); !equalKinds(got, []pointerEventKind{pointerHover}) {
+		t.Errorf("expected hover only, got %v", got)
+	}
+	if got := kinds(p.next(12, 10, false, true)); !equalKinds(got, []pointerEventKind{pointerHover, pointerRelease}) {
+		t.Errorf("expected hover and release, got %v", got)
+	}
+}
+
+func TestPointerTrackerDrag(t *testing.T) {
+	p := &pointerTracker{}
+
+	// タッチなので hover は起きない
+	p.next(10, 10, true, false)
+
+	events := p.next(30, 10, true, false)
+	if got := kinds(events); !equalKinds(got, []pointerEventKind{pointerDragStart, pointerDragMove}) {
+		t.Fatalf("expected drag start and move, got %v", got)
+	}
+	if events[0].x != 10 || events[0].y != 10 {
+		t.Errorf("expected drag to start at the pressed position, got (%d, %d)", events[0].x, events[0].y)
+	}
+
+	// 動いていなければ何も起きない
+	if got := kinds(p.next(30, 10, true, false)); len(got) != 0 {
+		t.Errorf("expected no events, got %v", got)
+	}
+
+	events = p.next(0, 0, false, false)
+	if got := kinds(events); !equalKinds(got, []pointerEventKind{pointerDragEnd, pointerRelease}) {
+		t.Fatalf("expected drag end and release, got %v", got)
+	}
+	// タッチは離した位置がとれないので最後に見た位置を使う
+	if events[0].x != 30 || events[0].y != 10 {
+		t.Errorf("expected drag to end at the last position, got (%d, %d)", events[0].x, events[0].y)
+	}
+}
@@ -5,10 +5,10 @@ import (
 	"fmt"
 	"image"
 	"log"
-	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/pankona/gj/sim"
 
 	_ "embed"
 	"image/color"
@@ -21,36 +21,27 @@ var radioTowerImageData []byte
 type radioTower struct {
 	game *Game
 
-	x, y          int
+	// シミュレーション上の建物
+	model *sim.Building
+
 	width, height int
 	zindex        int
 	image         *ebiten.Image
 
-	health           int
-	shortAttackRange float64
-	longAttackRange  float64
-	attackZoneRadius float64
-	attackPower      int
-	cooldown         int
-	erapsedTime      int // 攻撃実行からの経過時間
-
 	// 画像の拡大率。
 	// 1以外を指定する場合は元画像のサイズをそもそも変更できないか検討すること
 	scale float64
 
-	// 死亡時のアニメーションを管理するための変数
-	deadAnimationDuration int
-
-	// health が 0 になったときに呼ばれる関数
-	onDestroy func(b *radioTower)
-
 	// この建物が他の建物と重なっているかどうか (建築確定前に用いるフラグ)
 	isOverlapping bool
+
+	// 壊れたときに崩れるアニメーション
+	collapse *Animation
 }
 
-const radioTowerAttackCoolDown = 60
+func newRadioTower(game *Game, x, y int) *radioTower {
+	loadAnimations()
 
-func newRadioTower(game *Game, x, y int, onDestroy func(b *radioTower)) *radioTower {
 	img, _, err := image.Decode(bytes.NewReader(radioTowerImageData))
 	if err != nil {
 		log.Fatal(err)
@@ -59,91 +50,18 @@ func newRadioTower(game *Game, x, y int, onDestroy func(b *radioTower)) *radioTo
 	h := &radioTower{
 		game: game,
 
-		x:      x,
-		y:      y,
+		model: sim.NewBuilding(sim.BuildingRadioTower, x, y),
+
 		width:  img.Bounds().Dx(),
 		height: img.Bounds().Dy(),
 		scale:  1,
 
-		health: 50,
-
-		// 近すぎる敵は攻撃できない
-		// 最長攻撃可能距離と、最短攻撃可能距離を設定する
-		shortAttackRange: 200,
-		longAttackRange:  400,
-		attackZoneRadius: 50,
-
-		attackPower: 5,
-
 		image: ebiten.NewImageFromImage(img),
-
-		onDestroy: onDestroy,
 	}
 
 	return h
 }
 
-func (t *radioTower) Update() {
-	if t.game.phase == PhaseBuilding {
-		// do nothing
-		return
-	}
-
-	// 死亡時のアニメーションを再生する
-	if t.health <= 0 {
-		t.deadAnimationDuration++
-		if t.deadAnimationDuration >= deadAnimationTotalFrame {
-			t.onDestroy(t)
-		}
-		return
-	}
-
-	// 敵が攻撃範囲に入ってきたら攻撃する
-	// 複数の敵が攻撃範囲に入ってきた場合は、最も近い敵を攻撃する
-	// ただし近すぎる敵には攻撃できない
-
-	// shortAttackRange と longAttackRange の間にいる敵のうち、最も近い敵を探す
-	var nearestEnemy Enemy
-	nearestDistance := math.MaxFloat64
-	for _, e := range t.game.enemies {
-		ex, ey := e.Position()
-		// 敵が shortAttackRange と longAttackRange の間にいるかどうかを判定する
-		distance := math.Sqrt(math.Pow(float64(t.x-ex), 2) + math.Pow(float64(t.y-ey), 2))
-		if t.shortAttackRange < distance && distance < t.longAttackRange {
-			if distance < nearestDistance {
-				nearestEnemy = e
-				nearestDistance = distance
-			}
-		}
-	}
-
-	// クールダウンが明けていて、攻撃可能な敵がいる場合は攻撃する
-	if t.cooldown <= 0 && nearestEnemy != nil {
-		// nearestEnemy を中心に範囲攻撃を行う
-		ex, ey := nearestEnemy.Position()
-		for _, e := range t.game.enemies {
-			ex2, ey2 := e.Position()
-			distance := math.Sqrt(math.Pow(float64(ex-ex2), 2) + math.Pow(float64(ey-ey2), 2))
-			if distance < t.attackZoneRadius {
-				getAudioPlayer().play(soundBakuhatsu)
-
-				b := e.(Damager)
-				b.Damage(t.attackPower)
-
-				// エフェクトを描画する
-				eff := newRadioTowerAttackEffect(t.game, t.x, t.y, ex, ey, t.attackZoneRadius)
-				t.game.drawHandler.Add(eff)
-			}
-		}
-
-		t.cooldown = radioTowerAttackCoolDown
-	}
-
-	if t.cooldown > 0 {
-		t.cooldown--
-	}
-}
-
 // タワーから発射されるビームを描画するための構造体
 type radioTowerAttackEffect struct {
 	game *Game
@@ -189,25 +107,19 @@ func (b *radioTowerAttackEffect) ZIndex() int {
 
 // 画面中央に配置
 func (b *radioTower) Draw(screen *ebiten.Image) {
+	m := b.model
+
 	// 画像を描画
 	opts := &ebiten.DrawImageOptions{}
 
-	if b.health <= 0 {
+	img := b.image
+	if b.collapse != nil {
 		// 死亡時のアニメーションを行う
-		// ぺちゃんこになるように縮小する
-		scale := 1.0 - float64(b.deadAnimationDuration)/deadAnimationTotalFrame
-		if scale < 0 {
-			scale = 0
-		}
-
-		opts.GeoM.Translate(0, float64(-b.height))
-		opts.GeoM.Scale(1, scale)
-		opts.GeoM.Translate(0, float64(b.height))
-	} else {
-		opts.GeoM.Scale(b.scale, b.scale)
+		img = b.collapse.Frame()
 	}
+	opts.GeoM.Scale(b.scale, b.scale)
 
-	opts.GeoM.Translate(float64(b.x)-float64(b.width)*b.scale/2, float64(b.y)-float64(b.height)*b.scale/2)
+	opts.GeoM.Translate(float64(m.X)-float64(b.width)*b.scale/2, float64(m.Y)-float64(b.height)*b.scale/2)
 
 	// 他の建物と重なっている場合は赤くする
 	if b.isOverlapping {
@@ -217,40 +129,42 @@ func (b *radioTower) Draw(screen *ebiten.Image) {
 		opts.ColorScale.Scale(0.5, 0.5, 0.5, 1)
 	}
 
-	screen.DrawImage(b.image, opts)
+	screen.DrawImage(img, opts)
+}
+
+// 壊れたら崩れるアニメーションを始める
+func (b *radioTower) Update() {
+	if b.collapse == nil {
+		if b.model.Health > 0 {
+			return
+		}
+		b.collapse = newAnimation(buildingCollapseClips[b.model.Kind], nil)
+	}
+	b.collapse.Update()
 }
 
 func (b *radioTower) ZIndex() int {
 	return b.zindex
 }
 
+func (b *radioTower) Model() *sim.Building {
+	return b.model
+}
+
 func (b *radioTower) Position() (int, int) {
-	return b.x, b.y
+	return b.model.Position()
 }
 
 func (b *radioTower) SetPosition(x, y int) {
-	b.x = x
-	b.y = y
+	b.model.SetPosition(x, y)
 }
 
 func (b *radioTower) Size() (int, int) {
-	return int(float64(b.width) * b.scale), int(float64(b.height) * b.scale)
+	return b.model.Size()
 }
 
 func (b *radioTower) Name() string {
-	return "RadioTower"
-}
-
-func (b *radioTower) Damage(d int) {
-	if b.health <= 0 {
-		return
-	}
-
-	b.health -= d
-	if b.health <= 0 {
-		getAudioPlayer().play(soundKuzureru)
-		b.health = 0
-	}
+	return b.model.Name()
 }
 
 // radioTower implements Clickable interface
@@ -290,12 +204,13 @@ func newRadioTowerIcon(x, y int) *icon {
 }
 
 func (b *radioTower) Health() int {
-	return b.health
+	return b.model.Health
 }
 
 func (b *radioTower) IsClicked(x, y int) bool {
+	bx, by := b.Position()
 	w, h := b.Size()
-	return b.x-w/2 <= x && x <= b.x+w/2 && b.y-h/2 <= y && y <= b.y+h/2
+	return bx-w/2 <= x && x <= bx+w/2 && by-h/2 <= y && y <= by+h/2
 }
 
 func (b *radioTower) SetOverlap(overlap bool) {
@@ -304,25 +219,9 @@ func (b *radioTower) SetOverlap(overlap bool) {
 
 func (b *radioTower) IsOverlap() bool {
 	// 他の建物と重なっているかどうかを判定する
-	for _, building := range b.game.buildings {
-		if building == b {
-			continue
-		}
-
-		bx, by := building.Position()
-		bw, bh := building.Size()
-
-		if intersects(
-			rect{b.x - b.width/2, b.y - b.height/2, b.width, b.height},
-			rect{bx - bw/2, by - bh/2, bw, bh},
-		) {
-			return true
-		}
-	}
-
-	return false
+	return b.game.world.Overlaps(b.model)
 }
 
 func (b *radioTower) Cost() int {
-	return CostRadioTowerBuild
+	return b.model.Cost()
 }
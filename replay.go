@@ -0,0 +1,308 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/pankona/gj/sim"
+)
+
+// リプレイファイルの形式のバージョン
+// 形式を変えたら上げる
+const replayVersion = 3
+
+// 記録しておくリプレイファイルのパス
+const defaultReplayPath = "replay.json"
+
+type inputKind string
+
+const (
+	// クリック (getClickedPosition が返したもの)
+	inputClick inputKind = "click"
+	// マウスやタッチの状態が前のフレームから変わった
+	inputPointer inputKind = "pointer"
+	// キーが押された
+	inputKey inputKind = "key"
+)
+
+// inputRecord は記録された 1 つの入力
+type inputRecord struct {
+	// 記録を始めてから何フレーム目か
+	Frame int       `json:"frame"`
+	Kind  inputKind `json:"kind"`
+	X     int       `json:"x,omitempty"`
+	Y     int       `json:"y,omitempty"`
+
+	// Kind が pointer のときに使う
+	Down  bool `json:"down,omitempty"`
+	Hover bool `json:"hover,omitempty"`
+
+	// Kind が key のときに使う
+	Key *ebiten.Key `json:"key,omitempty"`
+}
+
+// replayData はリプレイファイルの中身
+// 同じシードで始めて同じ入力を同じフレームに与えれば、同じゲームになる
+type replayData struct {
+	Version int           `json:"version"`
+	Seed    int64         `json:"seed"`
+	Inputs  []inputRecord `json:"inputs"`
+
+	Endless    bool           `json:"endless,omitempty"`
+	Difficulty sim.Difficulty `json:"difficulty"`
+
+	// セーブデータから再開したゲームのときは、その状態から始める
+	Resume *sim.Snapshot `json:"resume,omitempty"`
+
+	// -stats で能力値を上書きしたときは、そのファイルのハッシュ
+	// 能力値が違うと同じゲームにならないので、再生するときに同じものか確かめる
+	Stats string `json:"stats,omitempty"`
+}
+
+// statsHash は能力値を上書きするファイルの中身からハッシュを作る
+func statsHash(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// pointerState はあるフレームのマウスやタッチの状態
+// pointerTracker はこれを受け取って pointerEvent を作る
+type pointerState struct {
+	x, y  int
+	down  bool
+	hover bool
+}
+
+// frameInput は 1 フレームぶんの入力
+// Game.Update はデバイスから直接読まずに、これを通して入力を受け取る
+type frameInput struct {
+	pointer pointerState
+
+	clickX, clickY int
+	clicked        bool
+
+	keys []ebiten.Key
+}
+
+// inputReader はいまのマウス、タッチ、キーボードの状態を読む
+// タッチは離したフレームには位置がとれないので、押している間の状態を覚えておく
+type inputReader struct {
+	// 押しているのがタッチかどうか
+	touching bool
+	// 最後にタッチしていた位置
+	lastX, lastY int
+}
+
+// read はこのフレームの入力を読む
+func (r *inputReader) read() frameInput {
+	var in frameInput
+
+	touchIDs := ebiten.AppendTouchIDs(nil)
+	switch {
+	case len(touchIDs) > 0:
+		// タッチにはカーソルがないので hover は起きない
+		x, y := ebiten.TouchPosition(touchIDs[0])
+		in.pointer = pointerState{x: x, y: y, down: true}
+		r.touching = true
+		r.lastX, r.lastY = x, y
+	case r.touching:
+		// タッチを離したフレームは、最後にタッチしていた位置で離したことにする
+		// マウスのカーソルの位置は関係ないので hover も起きない
+		in.pointer = pointerState{x: r.lastX, y: r.lastY}
+		r.touching = false
+	default:
+		x, y := ebiten.CursorPosition()
+		in.pointer = pointerState{x: x, y: y, down: ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft), hover: true}
+	}
+
+	in.clickX, in.clickY, in.clicked = getClickedPosition()
+	in.keys = inpututil.AppendJustPressedKeys(nil)
+
+	return in
+}
+
+// keyJustPressed はこのフレームで key が押されたかどうかを返す
+func (in frameInput) keyJustPressed(key ebiten.Key) bool {
+	for _, k := range in.keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// inputRecorder はゲーム本編の入力をフレームごとに記録する
+type inputRecorder struct {
+	data  replayData
+	frame int
+
+	// 前のフレームのポインタの状態
+	// 変わったときだけ記録する
+	last    pointerState
+	hasLast bool
+}
+
+func newInputRecorder(seed int64, endless bool, difficulty sim.Difficulty, resume *sim.Snapshot, stats string) *inputRecorder {
+	return &inputRecorder{
+		data: replayData{
+			Version:    replayVersion,
+			Seed:       seed,
+			Endless:    endless,
+			Difficulty: difficulty,
+			Resume:     resume,
+			Stats:      stats,
+		},
+	}
+}
+
+// record は 1 フレームぶんの入力を記録して、次のフレームに進む
+func (r *inputRecorder) record(in frameInput) {
+	if !r.hasLast || in.pointer != r.last {
+		p := in.pointer
+		r.data.Inputs = append(r.data.Inputs, inputRecord{Frame: r.frame, Kind: inputPointer, X: p.x, Y: p.y, Down: p.down, Hover: p.hover})
+		r.last = p
+		r.hasLast = true
+	}
+	if in.clicked {
+		r.data.Inputs = append(r.data.Inputs, inputRecord{Frame: r.frame, Kind: inputClick, X: in.clickX, Y: in.clickY})
+	}
+	for _, k := range in.keys {
+		k := k
+		r.data.Inputs = append(r.data.Inputs, inputRecord{Frame: r.frame, Kind: inputKey, Key: &k})
+	}
+	r.frame++
+}
+
+func (r *inputRecorder) save(path string) error {
+	b, err := json.Marshal(r.data)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// inputPlayer は記録された入力をフレームごとに返す
+type inputPlayer struct {
+	data  replayData
+	frame int
+
+	// 次に返す data.Inputs の添字
+	next int
+
+	// ポインタの状態は変わるまで前のフレームのものを使う
+	pointer pointerState
+}
+
+func newInputPlayer(data replayData) *inputPlayer {
+	return &inputPlayer{
+		data: data,
+	}
+}
+
+// loadReplay はリプレイファイルを読む
+// stats はいまの能力値の上書きのハッシュで、記録したときと違えば再生できない
+func loadReplay(path string, stats string) (replayData, error) {
+	var data replayData
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return data, err
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return data, err
+	}
+	if data.Version != replayVersion {
+		return data, fmt.Errorf("unsupported replay version: %d", data.Version)
+	}
+	if data.Stats != stats {
+		return data, fmt.Errorf("stats do not match the replay: recorded %q, got %q", data.Stats, stats)
+	}
+	return data, nil
+}
+
+// input は記録されたこのフレームの入力を返して、次のフレームに進む
+func (p *inputPlayer) input() frameInput {
+	var in frameInput
+	for ; p.next < len(p.data.Inputs); p.next++ {
+		r := p.data.Inputs[p.next]
+		if r.Frame != p.frame {
+			break
+		}
+
+		switch r.Kind {
+		case inputPointer:
+			p.pointer = pointerState{x: r.X, y: r.Y, down: r.Down, hover: r.Hover}
+		case inputClick:
+			in.clickX, in.clickY, in.clicked = r.X, r.Y, true
+		case inputKey:
+			if r.Key != nil {
+				in.keys = append(in.keys, *r.Key)
+			}
+		}
+	}
+	in.pointer = p.pointer
+	p.frame++
+
+	return in
+}
+
+// finished は記録された入力をすべて返し終わったかどうかを返す
+func (p *inputPlayer) finished() bool {
+	return p.next >= len(p.data.Inputs)
+}
+
+// startRecording はゲーム本編の入力を一から記録しなおす
+// 記録は次の Game.Update から始まる
+func (g *Game) startRecording() {
+	g.recorder = newInputRecorder(g.seed, g.endless, g.difficulty, g.resume, g.statsHash)
+	g.pointer = pointerTracker{}
+}
+
+// saveReplay はここまでの入力をリプレイファイルに書き出す
+// 再生中は書き出さない
+func (g *Game) saveReplay() {
+	if g.player != nil || g.recorder == nil || g.replayPath == "" {
+		return
+	}
+	if err := g.recorder.save(g.replayPath); err != nil {
+		log.Printf("failed to save replay: %v", err)
+	}
+}
+
+// startPlayback は data の入力を再生しながらゲーム本編を始める
+// タイトルは出さずに、記録したときと同じシードで始める
+func (g *Game) startPlayback(data replayData) {
+	g.seed = data.Seed
+	g.seedFixed = true
+	g.endless = data.Endless
+	g.difficulty = data.Difficulty
+	g.resume = data.Resume
+	g.player = newInputPlayer(data)
+	g.scenes.Switch(newPlayScene(g))
+}
+
+// nextInput はこのフレームの入力を返す
+// 再生中は記録された入力を、そうでなければデバイスの入力を返す
+func (g *Game) nextInput() frameInput {
+	var in frameInput
+	if g.player != nil {
+		in = g.player.input()
+		if g.player.finished() {
+			// 再生し終わったら、そこからは操作できるようにする
+			g.player = nil
+		}
+	} else {
+		in = g.reader.read()
+	}
+
+	if g.recorder != nil {
+		g.recorder.record(in)
+	}
+	return in
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/pankona/gj/sim"
+)
+
+func TestInputRecorderAndPlayer(t *testing.T) {
+	inputs := []frameInput{
+		{pointer: pointerState{x: 10, y: 10, hover: true}},
+		{pointer: pointerState{x: 10, y: 10, hover: true}},
+		{pointer: pointerState{x: 20, y: 30, down: true, hover: true}, clickX: 20, clickY: 30, clicked: true},
+		{pointer: pointerState{x: 20, y: 30, hover: true}, keys: []ebiten.Key{ebiten.KeyP}},
+		{pointer: pointerState{x: 20, y: 30, hover: true}},
+	}
+
+	r := newInputRecorder(42, false, sim.DifficultyNormal, nil, "")
+	for _, in := range inputs {
+		r.record(in)
+	}
+
+	// ポインタは変わったときだけ記録される
+	if len(r.data.Inputs) != 5 {
+		t.Fatalf("expected 5 records, got %d", len(r.data.Inputs))
+	}
+	if r.data.Seed != 42 {
+		t.Errorf("expected seed 42, got %d", r.data.Seed)
+	}
+
+	p := newInputPlayer(r.data)
+	for i, want := range inputs {
+		got := p.input()
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("frame %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+	if !p.finished() {
+		t.Errorf("expected the player to be finished")
+	}
+}
+
+func TestLoadReplayStats(t *testing.T) {
+	stats := statsHash([]byte(`{"tower": {"attackPower": 10}}`))
+
+	path := filepath.Join(t.TempDir(), "replay.json")
+	r := newInputRecorder(42, false, sim.DifficultyNormal, nil, stats)
+	if err := r.save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := loadReplay(path, stats); err != nil {
+		t.Errorf("expected the replay to be loaded with the same stats, got %v", err)
+	}
+	// 能力値を上書きしていない、あるいは違うもので上書きしているときは再生できない
+	if _, err := loadReplay(path, ""); err == nil {
+		t.Errorf("expected an error without the stats override")
+	}
+	if _, err := loadReplay(path, statsHash([]byte(`{}`))); err == nil {
+		t.Errorf("expected an error with different stats")
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/pankona/gj/sim"
+)
+
+// セーブデータを書いておく場所の名前
+// デスクトップでは save.json、ブラウザでは localStorage の gj-save に書かれる
+const saveStorageName = "save"
+
+// loadSnapshot はセーブデータを読んで、再開できるものであれば返す
+func loadSnapshot() (*sim.Snapshot, error) {
+	b, err := readStorage(saveStorageName)
+	if err != nil {
+		return nil, err
+	}
+
+	var s sim.Snapshot
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+
+	// 中身がおかしくないか確かめておく
+	if _, err := sim.RestoreWorld(s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// saveProgress は建築フェーズの始まりの状態をセーブデータに書く
+// 再生中は書かない
+func (g *Game) saveProgress() {
+	if g.player != nil {
+		return
+	}
+
+	b, err := json.Marshal(g.world.Snapshot())
+	if err != nil {
+		log.Printf("failed to save: %v", err)
+		return
+	}
+	if err := writeStorage(saveStorageName, b); err != nil {
+		log.Printf("failed to save: %v", err)
+	}
+}
+
+// clearProgress はセーブデータを消す
+// ゲームが終わったら、もう続きから始めることはできない
+func (g *Game) clearProgress() {
+	if g.player != nil {
+		return
+	}
+
+	if err := deleteStorage(saveStorageName); err != nil {
+		log.Printf("failed to delete save data: %v", err)
+	}
+}
+
+// Continue はセーブデータ s の続きからゲーム本編を始める
+func (g *Game) Continue(s *sim.Snapshot) {
+	g.seed = s.Seed
+	g.endless = s.Endless
+	g.difficulty = s.Difficulty
+	g.resume = s
+	g.scenes.Switch(newPlayScene(g))
+}
+
+// restoreWorld は g.resume から World を作り、置かれていた建物を描画できるようにする
+func (g *Game) restoreWorld() {
+	w, err := sim.RestoreWorld(*g.resume)
+	if err != nil {
+		// 読んだときに確かめているので、ここで失敗することはないはず
+		log.Fatal(err)
+	}
+	g.world = w
+
+	for _, b := range w.Buildings {
+		if b == w.House {
+			continue
+		}
+		g.entities.Register(b.ID, newBuildingView(g, b))
+	}
+}
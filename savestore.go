@@ -0,0 +1,27 @@
+//go:build !js
+
+package main
+
+import "os"
+
+// デスクトップでは name.json というファイルに書く
+
+func storagePath(name string) string {
+	return name + ".json"
+}
+
+func readStorage(name string) ([]byte, error) {
+	return os.ReadFile(storagePath(name))
+}
+
+func writeStorage(name string, b []byte) error {
+	return os.WriteFile(storagePath(name), b, 0o644)
+}
+
+func deleteStorage(name string) error {
+	err := os.Remove(storagePath(name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,38 @@
+//go:build js
+
+package main
+
+import (
+	"errors"
+	"syscall/js"
+)
+
+// ブラウザでは localStorage の gj-name というキーに書く
+
+var errNoStorageData = errors.New("no data in localStorage")
+
+func localStorage() js.Value {
+	return js.Global().Get("localStorage")
+}
+
+func storageKey(name string) string {
+	return "gj-" + name
+}
+
+func readStorage(name string) ([]byte, error) {
+	v := localStorage().Call("getItem", storageKey(name))
+	if v.IsNull() {
+		return nil, errNoStorageData
+	}
+	return []byte(v.String()), nil
+}
+
+func writeStorage(name string, b []byte) error {
+	localStorage().Call("setItem", storageKey(name), string(b))
+	return nil
+}
+
+func deleteStorage(name string) error {
+	localStorage().Call("removeItem", storageKey(name))
+	return nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Scene はタイトル、ゲーム本編、ゲームオーバーなどの場面をあらわす
+// シーンはスタックに積んで管理する。ポーズ画面や設定画面はいまのシーンの上に積んで、閉じたら取り除く
+type Scene interface {
+	// シーンがスタックに積まれたときに呼ばれる
+	OnEnter()
+	// シーンがスタックから取り除かれたときに呼ばれる
+	OnExit()
+
+	// クリックやポインタのイベントはスタックの一番上のシーンだけが受け取る
+	HandleClick(x, y int)
+	HandlePointer(e pointerEvent)
+	Update()
+	Draw(screen *ebiten.Image)
+}
+
+// overlayScene は下のシーンの更新を止めずに上に重なるシーンが実装する
+// ゲームオーバー画面のように、後ろでゲームが動き続けていてほしいものに使う
+type overlayScene interface {
+	Scene
+	UpdatesBelow() bool
+}
+
+// handlerSet はシーンやフェーズごとに持つハンドラの組
+// シーンやフェーズが終わったら handlerSet ごと捨てるので、個別に Remove して回る必要はない
+type handlerSet struct {
+	clickHandler  *OnClickHandler
+	drawHandler   *DrawHandler
+	updateHandler *UpdateHandler
+}
+
+func newHandlerSet() *handlerSet {
+	return &handlerSet{
+		clickHandler:  &OnClickHandler{},
+		drawHandler:   &DrawHandler{},
+		updateHandler: &UpdateHandler{},
+	}
+}
+
+// 以下は handlerSet を埋め込んだシーンのためのデフォルト実装
+
+func (h *handlerSet) OnEnter() {}
+
+func (h *handlerSet) OnExit() {}
+
+func (h *handlerSet) HandleClick(x, y int) {
+	h.clickHandler.HandleClick(x, y)
+}
+
+func (h *handlerSet) HandlePointer(e pointerEvent) {
+	h.clickHandler.HandlePointer(e)
+}
+
+func (h *handlerSet) Update() {
+	h.updateHandler.HandleUpdate()
+}
+
+func (h *handlerSet) Draw(screen *ebiten.Image) {
+	h.drawHandler.HandleDraw(screen)
+}
+
+type sceneManager struct {
+	// [0] が一番下のシーン
+	scenes []Scene
+}
+
+func (m *sceneManager) Push(s Scene) {
+	m.scenes = append(m.scenes, s)
+	s.OnEnter()
+}
+
+func (m *sceneManager) Pop() {
+	if len(m.scenes) == 0 {
+		return
+	}
+
+	s := m.scenes[len(m.scenes)-1]
+	m.scenes = m.scenes[:len(m.scenes)-1]
+	s.OnExit()
+}
+
+// Switch は積まれているシーンをすべて取り除いてから s を積む
+func (m *sceneManager) Switch(s Scene) {
+	for len(m.scenes) > 0 {
+		m.Pop()
+	}
+	m.Push(s)
+}
+
+func (m *sceneManager) Top() Scene {
+	if len(m.scenes) == 0 {
+		return nil
+	}
+	return m.scenes[len(m.scenes)-1]
+}
+
+func (m *sceneManager) HandleClick(x, y int) {
+	if top := m.Top(); top != nil {
+		top.HandleClick(x, y)
+	}
+}
+
+func (m *sceneManager) HandlePointer(e pointerEvent) {
+	if top := m.Top(); top != nil {
+		top.HandlePointer(e)
+	}
+}
+
+func (m *sceneManager) Update() {
+	if len(m.scenes) == 0 {
+		return
+	}
+
+	// 一番上のシーンは必ず更新する
+	// その下のシーンは、上に積まれているシーンがすべて UpdatesBelow を返すときだけ更新する
+	bottom := len(m.scenes) - 1
+	for bottom > 0 {
+		o, ok := m.scenes[bottom].(overlayScene)
+		if !ok || !o.UpdatesBelow() {
+			break
+		}
+		bottom--
+	}
+
+	// Update の中でシーンが積まれたり取り除かれたりしてもいいようにコピーしてから回す
+	scenes := append([]Scene{}, m.scenes[bottom:]...)
+	for _, s := range scenes {
+		s.Update()
+	}
+}
+
+func (m *sceneManager) Draw(screen *ebiten.Image) {
+	// 下のシーンから順に描画する
+	for _, s := range m.scenes {
+		s.Draw(screen)
+	}
+}
+
+// ゲーム本編のシーン
+// 建築フェーズとウェーブフェーズを行き来する
+type playScene struct {
+	*handlerSet
+
+	game *Game
+}
+
+func newPlayScene(g *Game) *playScene {
+	return &playScene{
+		handlerSet: newHandlerSet(),
+		game:       g,
+	}
+}
+
+func (s *playScene) OnEnter() {
+	g := s.game
+
+	// ゲーム中のオブジェクトはこのシーンのハンドラに登録してもらう
+	g.clickHandler = s.clickHandler
+	g.drawHandler = s.drawHandler
+	g.updateHandler = s.updateHandler
+	g.entities = newEntityRegistry(g)
+	g.buildCandidate = nil
+
+	g.initialize()
+	g.SetBuildingPhase()
+}
+
+func (s *playScene) HandleClick(x, y int) {
+	// フェーズごとのパネルはシーン全体のオブジェクトより手前にあるので先に処理する
+	// 貫通してきたクリックだけをシーン全体のハンドラに渡す
+	if s.game.phaseHandlers.clickHandler.HandleClick(x, y) {
+		s.clickHandler.HandleClick(x, y)
+	}
+}
+
+func (s *playScene) HandlePointer(e pointerEvent) {
+	// クリックと同じく、フェーズごとのパネルで貫通してきたものだけをシーン全体のハンドラに渡す
+	if s.game.phaseHandlers.clickHandler.HandlePointer(e) {
+		s.clickHandler.HandlePointer(e)
+		return
+	}
+
+	// カーソルがさえぎられたら、シーン全体のオブジェクトからは離れたことにする
+	if e.kind == pointerHover {
+		s.clickHandler.HandlePointer(pointerEvent{kind: pointerLeave})
+	}
+}
+
+func (s *playScene) Update() {
+	g := s.game
+
+	// ゲームオーバー画面などが上に積まれているときはキーボードでの操作を受け付けない
+	if g.scenes.Top() == Scene(s) {
+		g.speedCtrl.handleKeys()
+	}
+
+	// 一時停止中は 0 回、早送り中は倍率の回数だけ進める
+	for i := 0; i < g.speedCtrl.steps(); i++ {
+		s.updateHandler.HandleUpdate()
+		g.phaseHandlers.updateHandler.HandleUpdate()
+
+		// このフレームのシミュレーションで起きたことをまとめて処理する
+		// 音やエフェクトはここで鳴ったり出たりする
+		g.world.Events.Dispatch()
+
+		// ウェーブが終わったら残りは進めない
+		if g.phase != PhaseWave {
+			break
+		}
+	}
+}
+
+func (s *playScene) Draw(screen *ebiten.Image) {
+	s.drawHandler.HandleDraw(screen)
+	s.game.phaseHandlers.drawHandler.HandleDraw(screen)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// MockScene is a mock implementation of the Scene interface for testing purposes.
+type MockScene struct {
+	entered, exited bool
+	updated         int
+	clicked         int
+	updatesBelow    bool
+}
+
+func (m *MockScene) OnEnter()                     { m.entered = true }
+func (m *MockScene) OnExit()                      { m.exited = true }
+func (m *MockScene) HandleClick(x, y int)         { m.clicked++ }
+func (m *MockScene) HandlePointer(e pointerEvent) {}
+func (m *MockScene) Update()                      { m.updated++ }
+func (m *MockScene) Draw(screen *ebiten.Image)    {}
+
+type MockOverlayScene struct {
+	MockScene
+}
+
+func (m *MockOverlayScene) UpdatesBelow() bool { return m.updatesBelow }
+
+func TestSceneManagerPushPop(t *testing.T) {
+	m := &sceneManager{}
+	s1 := &MockScene{}
+	s2 := &MockScene{}
+
+	m.Push(s1)
+	m.Push(s2)
+
+	if !s1.entered || !s2.entered {
+		t.Errorf("expected both scenes to be entered")
+	}
+	if m.Top() != s2 {
+		t.Errorf("expected s2 to be on top")
+	}
+
+	m.Pop()
+
+	if !s2.exited {
+		t.Errorf("expected s2 to be exited")
+	}
+	if s1.exited {
+		t.Errorf("s1 should not be exited")
+	}
+	if m.Top() != s1 {
+		t.Errorf("expected s1 to be on top")
+	}
+}
+
+func TestSceneManagerSwitch(t *testing.T) {
+	m := &sceneManager{}
+	s1 := &MockScene{}
+	s2 := &MockScene{}
+	s3 := &MockScene{}
+
+	m.Push(s1)
+	m.Push(s2)
+	m.Switch(s3)
+
+	if !s1.exited || !s2.exited {
+		t.Errorf("expected all previous scenes to be exited")
+	}
+	if len(m.scenes) != 1 || m.Top() != s3 {
+		t.Errorf("expected only s3 to remain")
+	}
+}
+
+func TestSceneManagerUpdateOnlyTop(t *testing.T) {
+	m := &sceneManager{}
+	s1 := &MockScene{}
+	s2 := &MockScene{}
+
+	m.Push(s1)
+	m.Push(s2)
+	m.HandleClick(0, 0)
+	m.Update()
+
+	if s1.updated != 0 || s1.clicked != 0 {
+		t.Errorf("scene below should not be updated nor clicked")
+	}
+	if s2.updated != 1 || s2.clicked != 1 {
+		t.Errorf("top scene should be updated and clicked")
+	}
+}
+
+func TestSceneManagerUpdatesBelow(t *testing.T) {
+	m := &sceneManager{}
+	s1 := &MockScene{}
+	s2 := &MockOverlayScene{MockScene{updatesBelow: true}}
+
+	m.Push(s1)
+	m.Push(s2)
+	m.HandleClick(0, 0)
+	m.Update()
+
+	if s1.updated != 1 {
+		t.Errorf("scene below the overlay should be updated")
+	}
+	if s1.clicked != 0 {
+		t.Errorf("scene below the overlay should not be clicked")
+	}
+	if s2.updated != 1 || s2.clicked != 1 {
+		t.Errorf("top scene should be updated and clicked")
+	}
+}
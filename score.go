@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+)
+
+// エンドレスモードの最高記録を書いておく場所の名前
+const scoreStorageName = "score"
+
+// エンドレスモードの最高記録
+type bestScore struct {
+	// 乗り越えたウェーブの数
+	Waves int `json:"waves"`
+}
+
+// loadBestScore はエンドレスモードの最高記録を読む
+// まだ記録がなければ 0 を返す
+func loadBestScore() int {
+	b, err := readStorage(scoreStorageName)
+	if err != nil {
+		return 0
+	}
+
+	var s bestScore
+	if err := json.Unmarshal(b, &s); err != nil {
+		return 0
+	}
+	return s.Waves
+}
+
+// updateBestScore は乗り越えたウェーブの数が最高記録を超えていたら記録しなおす
+// 再生中は記録しない
+func (g *Game) updateBestScore(waves int) {
+	if g.player != nil || waves <= g.bestWaves {
+		return
+	}
+	g.bestWaves = waves
+
+	b, err := json.Marshal(bestScore{Waves: waves})
+	if err != nil {
+		log.Printf("failed to save score: %v", err)
+		return
+	}
+	if err := writeStorage(scoreStorageName, b); err != nil {
+		log.Printf("failed to save score: %v", err)
+	}
+}
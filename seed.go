@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// シードとして入力できる最大の桁数
+const maxSeedDigits = 9
+
+// newSeed はシードを決めていないときに使うシードを返す
+func newSeed() int64 {
+	return time.Now().UnixNano() % 1000000000
+}
+
+// seedInput はタイトル画面でシードを入力するためのもの
+// 数字を打つとシードを決められる。決めたシードはリセットしても変わらない
+type seedInput struct {
+	game *Game
+
+	text string
+}
+
+func newSeedInput(g *Game) *seedInput {
+	return &seedInput{
+		game: g,
+	}
+}
+
+func (s *seedInput) Update() {
+	changed := false
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if '0' <= r && r <= '9' && len(s.text) < maxSeedDigits {
+			s.text += string(r)
+			changed = true
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(s.text) > 0 {
+		s.text = s.text[:len(s.text)-1]
+		changed = true
+	}
+	if !changed {
+		return
+	}
+
+	// すべて消したらシードを決めていないことにする
+	if s.text == "" {
+		s.game.seedFixed = false
+		return
+	}
+
+	seed, err := strconv.ParseInt(s.text, 10, 64)
+	if err != nil {
+		return
+	}
+	s.game.seed = seed
+	s.game.seedFixed = true
+}
+
+func (s *seedInput) Draw(screen *ebiten.Image) {
+	clr := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	drawText(screen, fmt.Sprintf("SEED: %d", s.game.seed), screenWidth-750, 240, 3, 3, clr)
+	drawText(screen, "TYPE DIGITS TO SET SEED", screenWidth-750, 280, 2, 2, clr)
+}
+
+func (s *seedInput) ZIndex() int {
+	return 310
+}
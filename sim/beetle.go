@@ -0,0 +1,73 @@
+package sim
+
+// 甲羅がひっくり返ってから元に戻るまでのフレーム数
+const beetleFlipDuration = 180
+
+// 見た目は緑虫の色違い
+func init() {
+	registerBugKind(BugBeetle, bugKindSpec{
+		name:        "beetle",
+		width:       31,
+		height:      46,
+		combatPower: 4,
+		newBehavior: func() BugBehavior { return &beetleBugBehavior{} },
+	})
+}
+
+// 甲虫の特徴
+// 固い甲羅に守られていて、tower のビームも radioTower の爆発も効かない。
+// プレイヤーが叩くと甲羅がひっくり返り、しばらくの間はあらゆる攻撃が効くようになる。
+// ひっくり返っている間はもがいていて、動くことも攻撃することもできない。
+// 家に向かって進み、途中の建物に飛びかかる。
+type beetleBugBehavior struct {
+	houseBound
+
+	// 甲羅が元に戻るまでの残りフレーム数
+	flipFrame int
+}
+
+// 気絶している間も、甲羅が元に戻るまでの時間は進む
+func (bh *beetleBugBehavior) tickRecovery(w *World, b *Bug) {
+	if !b.Flipped {
+		return
+	}
+	bh.flipFrame--
+	if bh.flipFrame <= 0 {
+		b.Flipped = false
+	}
+}
+
+func (bh *beetleBugBehavior) Target(w *World, b *Bug) *Building {
+	if b.Flipped {
+		return nil
+	}
+	return bh.houseBound.Target(w, b)
+}
+
+func (bh *beetleBugBehavior) Attack(w *World, b *Bug, target *Building) {
+	b.attackWithLunge(w, target)
+}
+
+func (bh *beetleBugBehavior) modifyDamage(w *World, b *Bug, d int, source DamageSource) int {
+	if source == DamageHand {
+		// 叩かれたらひっくり返る。ひっくり返っている間に叩いても時間はのびない
+		if !b.Flipped {
+			b.Flipped = true
+			bh.flipFrame = beetleFlipDuration
+			w.Events.Publish(BugFlipped{Bug: b})
+
+			// 飛びかかっている途中なら元の位置に戻す
+			if b.attacking {
+				b.X, b.Y = b.originalX, b.originalY
+				b.attacking = false
+			}
+		}
+		return d
+	}
+
+	if !b.Flipped {
+		// 甲羅に弾かれる
+		return 0
+	}
+	return d
+}
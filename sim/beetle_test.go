@@ -0,0 +1,70 @@
+package sim
+
+import "testing"
+
+func TestBeetleArmor(t *testing.T) {
+	w, beetle := spawnNearHouse(t, BugBeetle)
+	health := beetle.Health
+
+	// 甲羅があるうちは塔の攻撃が効かない
+	beetle.Damage(w, 1, DamageTower)
+	beetle.Damage(w, 1, DamageRadioTower)
+	if beetle.Health != health {
+		t.Fatalf("expected the armor to block towers, got health %d", beetle.Health)
+	}
+
+	// 叩くとひっくり返って、塔の攻撃が効くようになる
+	beetle.Damage(w, 1, DamageHand)
+	if !beetle.Flipped || beetle.Health != health-1 {
+		t.Fatalf("expected the slap to flip the beetle, got flipped %v health %d", beetle.Flipped, beetle.Health)
+	}
+	beetle.Damage(w, 1, DamageTower)
+	if beetle.Health != health-2 {
+		t.Errorf("expected a flipped beetle to take tower damage, got health %d", beetle.Health)
+	}
+
+	// ひっくり返っている間は動かない
+	x := beetle.X
+	beetle.update(w)
+	if beetle.X != x {
+		t.Errorf("expected a flipped beetle not to move")
+	}
+
+	// しばらくすると元に戻る
+	for i := 0; i < beetleFlipDuration; i++ {
+		beetle.update(w)
+	}
+	if beetle.Flipped {
+		t.Errorf("expected the beetle to recover after %d frames", beetleFlipDuration)
+	}
+	beetle.Damage(w, 1, DamageTower)
+	if beetle.Health != health-2 {
+		t.Errorf("expected the armor to block towers again, got health %d", beetle.Health)
+	}
+}
+
+func TestBeetleArmorBlocksOnHit(t *testing.T) {
+	restoreStats(t)
+	err := LoadStats([]byte(`{"buildings": {"tower": {"onHit": {"kind": "slow", "duration": 30, "speedRate": 0.5}}}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w, beetle := spawnNearHouse(t, BugBeetle)
+	tower := NewBuilding(BuildingTower, beetle.X-100, beetle.Y)
+	w.AddBuilding(tower)
+
+	// 甲羅に弾かれたときは状態異常もかからない
+	tower.update(w)
+	if beetle.HasStatus(StatusSlow) {
+		t.Fatalf("expected the armor to block the on-hit status")
+	}
+
+	// ひっくり返っていれば、ダメージといっしょに状態異常もかかる
+	beetle.Damage(w, 1, DamageHand)
+	tower.cooldown = 0
+	tower.update(w)
+	if !beetle.HasStatus(StatusSlow) {
+		t.Errorf("expected a flipped beetle to be slowed by the tower")
+	}
+}
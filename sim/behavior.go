@@ -0,0 +1,169 @@
+package sim
+
+import "math"
+
+// BugBehavior は虫の種類ごとのふるまい
+// 新しい種類の虫を足すときは、これを実装して registerBugKind で登録する
+// ふるまいは虫ごとに作られるので、虫ごとの状態を持たせてもよい
+type BugBehavior interface {
+	// Tick は生きていて気絶していない間、毎フレーム Target より前に呼ばれる
+	// 移動や攻撃とは別に行うこと (段階を変える、手下を呼ぶなど) を書く
+	Tick(w *World, b *Bug)
+	// Target は攻撃する、あるいは向かっていく建物を返す
+	// nil を返したときはその場にとどまる
+	Target(w *World, b *Bug) *Building
+	// Move は攻撃範囲の外にいる target に向かって移動する
+	Move(w *World, b *Bug, target *Building)
+	// Attack は攻撃範囲の中にいる target を攻撃する
+	Attack(w *World, b *Bug, target *Building)
+	// OnDeath は死亡したときに一度だけ呼ばれる
+	OnDeath(w *World, b *Bug)
+}
+
+// damageModifier を実装したふるまいの虫は、受けるダメージが変わる
+// 受けるダメージを返す。0 を返したときは攻撃が効かなかったことになる
+type damageModifier interface {
+	modifyDamage(w *World, b *Bug, d int, source DamageSource) int
+}
+
+// recoveryTicker を実装したふるまいの虫は、気絶している間も元に戻るまでの時間が進む
+// 生きている間、毎フレーム Tick より前に呼ばれる
+type recoveryTicker interface {
+	tickRecovery(w *World, b *Bug)
+}
+
+// このファイルにある赤虫、青虫、緑虫、羽虫を登録する
+// ほかの虫はそれぞれのファイルで登録する
+func init() {
+	registerBugKind(BugRed, bugKindSpec{
+		name:        "red",
+		width:       28,
+		height:      40,
+		combatPower: 1,
+		newBehavior: func() BugBehavior { return redBugBehavior{} },
+	})
+	registerBugKind(BugBlue, bugKindSpec{
+		name:        "blue",
+		width:       29,
+		height:      41,
+		combatPower: 2,
+		newBehavior: func() BugBehavior { return blueBugBehavior{} },
+	})
+	registerBugKind(BugGreen, bugKindSpec{
+		name:        "green",
+		width:       31,
+		height:      46,
+		combatPower: 3,
+		newBehavior: func() BugBehavior { return greenBugBehavior{} },
+	})
+	// 見た目は青虫の色違い
+	registerBugKind(BugFly, bugKindSpec{
+		name:        "fly",
+		width:       29,
+		height:      41,
+		flying:      true,
+		combatPower: 2,
+		newBehavior: func() BugBehavior { return flyingBugBehavior{} },
+	})
+}
+
+// houseBound は攻撃範囲に建物が入ればそれを、そうでなければ house を目指す虫のふるまい
+// 移動と死亡時のふるまいも共通なので、ほかの虫はこれを埋め込んで使う
+type houseBound struct{}
+
+func (houseBound) Tick(w *World, b *Bug) {}
+
+func (houseBound) Target(w *World, b *Bug) *Building {
+	// attack target がいるならば攻撃する。そうでないならば house に向かう
+	if attackTarget := b.findBuildingInRange(w); attackTarget != nil {
+		return attackTarget
+	}
+
+	if !w.houseExists() {
+		// すべての建物が破壊されている場合はその場にとどまる
+		return nil
+	}
+	return w.House
+}
+
+func (houseBound) Move(w *World, b *Bug, target *Building) {
+	x, y := target.Position()
+	b.moveTowards(w, x, y)
+}
+
+func (houseBound) OnDeath(w *World, b *Bug) {}
+
+// 赤虫の特徴
+// 家に向かって進む。途中で障害物が攻撃範囲に入ったら、それに飛びかかって攻撃する。
+type redBugBehavior struct{ houseBound }
+
+func (redBugBehavior) Attack(w *World, b *Bug, target *Building) {
+	b.attackWithLunge(w, target)
+}
+
+// 青虫の特徴
+// 最寄りの障害物に向かって進む。障害物にぶつかったら、ぶつかったものに対して攻撃を行う。
+// 攻撃は一定時間ごとに行う。攻撃機範囲はせまい。自身の周囲ちょっとくらい (赤虫と同じ)。
+// 体力は赤虫よりもちょっと多い。
+// 赤虫より多く出現する。
+// 動きの速さは普通。
+type blueBugBehavior struct{ houseBound }
+
+func (blueBugBehavior) Target(w *World, b *Bug) *Building {
+	// 最寄りの障害物を探す
+	nearestBuilding, _, ok := w.buildingIndex.nearest(b.X, b.Y, math.MaxFloat64, nil)
+	if !ok {
+		// すべての建物が破壊されている場合はその場にとどまる
+		return nil
+	}
+	return nearestBuilding
+}
+
+func (blueBugBehavior) Attack(w *World, b *Bug, target *Building) {
+	// クールダウン中でかつ攻撃対象が攻撃範囲内にいるときにはその場にとどまる
+	b.attackWithLunge(w, target)
+}
+
+// 緑虫の特徴
+// 家に向かって一直線に進む。
+// 攻撃は一定時間ごとに行う。攻撃範囲が広い。飛び道具のようなものを放つ。
+// 攻撃範囲に任意の障害物が入ったとき、その障害物に向かって攻撃を行う。
+// 体力は青虫よりも多い。
+// 出現頻度は低い。
+// 動きは遅い。
+type greenBugBehavior struct{ houseBound }
+
+func (greenBugBehavior) Attack(w *World, b *Bug, target *Building) {
+	// クールダウン中でなければ弾を撃つ
+	if b.attackCooldown <= 0 {
+		b.attack(w, target, true)
+		b.attackCooldown = b.AttackInterval
+	} else {
+		// クールダウンを消化する
+		b.attackCooldown -= 1
+	}
+}
+
+// 羽虫の特徴
+// 家に向かって飛んでいく。バリケードは飛び越えていくので足止めされない。
+// バリケード以外の建物が攻撃範囲に入ったら、それに飛びかかって攻撃する。
+// 地面を狙う radioTower の爆発は当たらない。tower のビームとプレイヤーの手は当たる。
+type flyingBugBehavior struct{ houseBound }
+
+func (flyingBugBehavior) Target(w *World, b *Bug) *Building {
+	for _, building := range w.buildingIndex.queryRect(b.attackRect()) {
+		if building.Kind != BuildingBarricade {
+			return building
+		}
+	}
+
+	if !w.houseExists() {
+		// すべての建物が破壊されている場合はその場にとどまる
+		return nil
+	}
+	return w.House
+}
+
+func (flyingBugBehavior) Attack(w *World, b *Bug, target *Building) {
+	b.attackWithLunge(w, target)
+}
@@ -0,0 +1,113 @@
+package sim
+
+import "testing"
+
+// 呼ばれたふるまいを記録する BugBehavior
+type MockBugBehavior struct {
+	houseBound
+
+	attacked int
+	died     int
+}
+
+func (m *MockBugBehavior) Attack(w *World, b *Bug, target *Building) {
+	m.attacked++
+}
+
+func (m *MockBugBehavior) OnDeath(w *World, b *Bug) {
+	m.died++
+}
+
+func TestBugKindRegistered(t *testing.T) {
+	for kind := BugRed; kind <= BugBeetle; kind++ {
+		spec, ok := bugKinds[kind]
+		if !ok {
+			t.Errorf("bug kind %d is not registered", kind)
+			continue
+		}
+		if spec.newBehavior == nil || spec.width <= 0 || spec.height <= 0 || spec.combatPower <= 0 {
+			t.Errorf("%s: invalid registration %+v", spec.name, spec)
+		}
+		if got, ok := bugKindByName(spec.name); !ok || got != kind {
+			t.Errorf("%s: expected to find kind %d by name, got %d", spec.name, kind, got)
+		}
+	}
+}
+
+func TestBugBehaviorHooks(t *testing.T) {
+	w := NewWorld(1)
+	hx, hy := w.House.Position()
+
+	m := &MockBugBehavior{}
+	b := NewBug(BugRed, hx+200, hy)
+	b.behavior = m
+	w.AddBug(b)
+
+	// 家から離れているうちは近づいていく
+	b.update(w)
+	if b.X >= hx+200 || m.attacked != 0 {
+		t.Errorf("expected the bug to move towards the house, got x %d attacked %d", b.X, m.attacked)
+	}
+
+	// 家に隣接すれば攻撃する
+	b.X = hx + w.House.Width/2 + b.Width/2
+	b.update(w)
+	if m.attacked != 1 {
+		t.Errorf("expected the bug to attack the house, got %d", m.attacked)
+	}
+
+	// 死亡時のふるまいは一度だけ呼ばれる
+	b.Damage(w, b.Health, DamageHand)
+	b.Damage(w, 1, DamageHand)
+	if m.died != 1 {
+		t.Errorf("expected OnDeath to be called once, got %d", m.died)
+	}
+}
+
+func TestFlyingBugIgnoresBarricade(t *testing.T) {
+	w := NewWorld(1)
+	hx, hy := w.House.Position()
+	barricade := NewBuilding(BuildingBarricade, hx+300, hy)
+	w.AddBuilding(barricade)
+
+	// バリケードのすぐ右隣にいる
+	x := barricade.X + barricade.Width/2 + 15
+
+	red := NewBug(BugRed, x, hy)
+	w.AddBug(red)
+	if got := red.behavior.Target(w, red); got != barricade {
+		t.Errorf("expected a red bug to target the barricade, got %v", got)
+	}
+
+	fly := NewBug(BugFly, x, hy+1)
+	w.AddBug(fly)
+	if got := fly.behavior.Target(w, fly); got != w.House {
+		t.Errorf("expected a flying bug to fly over the barricade, got %v", got)
+	}
+}
+
+func TestRadioTowerMissesFlyingBug(t *testing.T) {
+	w := NewWorld(1)
+	hx, hy := w.House.Position()
+	radioTower := NewBuilding(BuildingRadioTower, hx, hy+200)
+	w.AddBuilding(radioTower)
+
+	// 射程の中に羽虫だけがいるときは撃たない
+	fly := NewBug(BugFly, radioTower.X+300, radioTower.Y)
+	w.AddBug(fly)
+	radioTower.update(w)
+	if fly.Health != BugStatsOf(BugFly).Health {
+		t.Errorf("expected the flying bug not to be damaged, got health %d", fly.Health)
+	}
+
+	// 地面の虫を狙った爆発に巻き込まれても当たらない
+	red := NewBug(BugRed, fly.X, fly.Y+10)
+	w.AddBug(red)
+	radioTower.update(w)
+	if !red.IsDead() {
+		t.Errorf("expected the red bug to be hit by the blast")
+	}
+	if fly.Health != BugStatsOf(BugFly).Health {
+		t.Errorf("expected the flying bug not to be hit by the blast, got health %d", fly.Health)
+	}
+}
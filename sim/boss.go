@@ -0,0 +1,105 @@
+package sim
+
+import "math"
+
+// ボスの画像の拡大率
+const bossScale = 3.0
+
+// 見た目は緑虫を大きくしたもの
+func init() {
+	registerBugKind(BugBoss, bugKindSpec{
+		name:        "boss",
+		width:       31,
+		height:      46,
+		scale:       bossScale,
+		combatPower: 30,
+		newBehavior: func() BugBehavior { return &bossBugBehavior{} },
+	})
+}
+
+// BossPhase はボスの段階
+// 体力が減るにつれて次の段階に移り、ふるまいが変わる。前の段階には戻らない
+type BossPhase int
+
+const (
+	// 家に向かって進み、途中の建物に飛びかかる
+	BossPhaseMarch BossPhase = iota
+	// 体力が 2/3 を切ったら、ときどき手下の赤虫を呼ぶ
+	BossPhaseSummon
+	// 体力が 1/3 を切ったら、手下を呼ぶのをやめて最寄りの建物に突進する
+	BossPhaseCharge
+)
+
+const (
+	// 手下を呼ぶ間隔 (フレーム数)
+	bossSummonInterval = 180
+	// 一度に呼ぶ手下の数
+	bossSummonCount = 4
+	// 突進するときの速さの倍率
+	bossChargeSpeed = 2.5
+)
+
+// ボスの特徴
+// 大きくて体力がとても多い。最後のウェーブに出てくる。
+// 体力が減ると段階が変わり、手下を呼んだり建物に突進したりする。
+type bossBugBehavior struct {
+	houseBound
+
+	phase          BossPhase
+	summonCooldown int
+}
+
+func (bh *bossBugBehavior) Tick(w *World, b *Bug) {
+	bh.updatePhase(w, b)
+
+	if bh.phase != BossPhaseSummon {
+		return
+	}
+	if bh.summonCooldown > 0 {
+		bh.summonCooldown--
+		return
+	}
+	bh.summon(w, b)
+	bh.summonCooldown = bossSummonInterval
+}
+
+// 体力に応じて段階を進める
+func (bh *bossBugBehavior) updatePhase(w *World, b *Bug) {
+	next := bh.phase
+	switch {
+	case b.Health*3 <= b.MaxHealth:
+		next = BossPhaseCharge
+	case b.Health*3 <= b.MaxHealth*2:
+		next = max(next, BossPhaseSummon)
+	}
+	if next == bh.phase {
+		return
+	}
+
+	bh.phase = next
+	if next == BossPhaseCharge {
+		b.Speed *= bossChargeSpeed
+	}
+	w.Events.Publish(BossPhaseChanged{Bug: b, Phase: next})
+}
+
+// summon はボスのまわりに手下の赤虫を出す
+func (bh *bossBugBehavior) summon(w *World, b *Bug) {
+	b.spawnAround(w, BugRed, bossSummonCount)
+}
+
+func (bh *bossBugBehavior) Target(w *World, b *Bug) *Building {
+	if bh.phase == BossPhaseCharge {
+		// 最寄りの建物に突進する
+		nearestBuilding, _, ok := w.buildingIndex.nearest(b.X, b.Y, math.MaxFloat64, nil)
+		if !ok {
+			return nil
+		}
+		return nearestBuilding
+	}
+	return bh.houseBound.Target(w, b)
+}
+
+func (bh *bossBugBehavior) Attack(w *World, b *Bug, target *Building) {
+	b.attackWithLunge(w, target)
+}
@@ -0,0 +1,94 @@
+package sim
+
+import "testing"
+
+func TestBossPhases(t *testing.T) {
+	w, boss := spawnNearHouse(t, BugBoss)
+	var phases []BossPhase
+	Subscribe(w.Events, func(e BossPhaseChanged) { phases = append(phases, e.Phase) })
+
+	speed := boss.Speed
+
+	// 体力が 2/3 を切ったら手下を呼ぶ
+	boss.Damage(w, boss.MaxHealth/2, DamageHand)
+	boss.update(w)
+	if len(w.Bugs) != 1+bossSummonCount {
+		t.Errorf("expected %d minions to be summoned, got %d bugs", bossSummonCount, len(w.Bugs))
+	}
+	for _, b := range w.Bugs[1:] {
+		if b.Kind != BugRed {
+			t.Errorf("expected minions to be red bugs, got %d", b.Kind)
+		}
+	}
+
+	// 次に呼ぶのは間隔をあけてから
+	boss.update(w)
+	if len(w.Bugs) != 1+bossSummonCount {
+		t.Errorf("expected no more minions during the cooldown, got %d bugs", len(w.Bugs))
+	}
+
+	// 体力が 1/3 を切ったら突進する
+	boss.Damage(w, boss.Health-1, DamageHand)
+	boss.update(w)
+	if boss.Speed != speed*bossChargeSpeed {
+		t.Errorf("expected the boss to charge at speed %v, got %v", speed*bossChargeSpeed, boss.Speed)
+	}
+
+	w.Events.Dispatch()
+	if len(phases) != 2 || phases[0] != BossPhaseSummon || phases[1] != BossPhaseCharge {
+		t.Errorf("expected the summon and charge phases, got %v", phases)
+	}
+}
+
+func TestBossArrivesAfterFieldCleared(t *testing.T) {
+	w := NewWorld(1)
+	var boss *Bug
+	var cleared bool
+	Subscribe(w.Events, func(e BugSpawned) {
+		if e.Bug.Kind == BugBoss {
+			boss = e.Bug
+		}
+	})
+	Subscribe(w.Events, func(WaveCleared) { cleared = true })
+
+	// 最後のウェーブで、ボスが出てくるまでに出てきた虫をすぐに倒していく
+	w.Wave = w.WaveCount() - 1
+	w.StartWave()
+	last := w.waves[w.Wave][len(w.waves[w.Wave])-1]
+	for frame := 0; frame <= last.spawnFrame; frame++ {
+		for _, b := range w.Bugs {
+			b.Damage(w, b.Health, DamageHand)
+		}
+		w.Step()
+		w.Events.Dispatch()
+	}
+
+	if cleared {
+		t.Fatalf("expected the wave not to be cleared before the boss arrives")
+	}
+	if boss == nil {
+		t.Errorf("expected the boss to arrive after the field is cleared")
+	}
+}
+
+func TestBossMarchesTowardsHouse(t *testing.T) {
+	w := NewWorld(1)
+	hx, hy := w.House.Position()
+
+	// 上の端から、家とは斜めの向きに出てくる
+	boss := NewBug(BugBoss, 200, -50)
+	w.AddBug(boss)
+	before := distance(boss.X, boss.Y, hx, hy)
+	for i := 0; i < 120; i++ {
+		boss.update(w)
+		w.bugIndex.update(boss)
+	}
+
+	// 突進しなくても家に近づいていく
+	if got := distance(boss.X, boss.Y, hx, hy); got > before-100 {
+		t.Errorf("expected the marching boss to get closer to the house, got %v from %v", got, before)
+	}
+	if boss.Y < 0 {
+		t.Errorf("expected the boss to walk into the field, got y %d", boss.Y)
+	}
+}
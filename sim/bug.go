@@ -0,0 +1,305 @@
+package sim
+
+import "math"
+
+type BugKind int
+
+const (
+	BugRed BugKind = iota
+	BugBlue
+	BugGreen
+	BugFly
+	BugBoss
+	BugQueen
+	BugHealer
+	BugKamikaze
+	BugBeetle
+)
+
+const (
+	// 死亡してから取り除かれるまでのフレーム数
+	// 描画側はこの間に死亡時のアニメーションを行う
+	DeadAnimationTotalFrame = 10
+)
+
+type Bug struct {
+	ID   EntityID
+	Kind BugKind
+
+	X, Y          int
+	Width, Height int
+
+	// 以下は stats.json の値で初期化される
+	Name   string
+	Health int
+	// 体力の最大値。回復や体力バーの表示に使う
+	MaxHealth   int
+	Speed       float64
+	AttackPower int
+	AttackRange float64
+	// 攻撃してから次に攻撃できるまでのフレーム数
+	AttackInterval int
+
+	// 攻撃クールダウン
+	// 初期化時に設定するものではなく、攻撃後に設定するもの
+	attackCooldown int
+
+	// 攻撃中であるかどうかを示すフラグと攻撃アニメーションの時間
+	// 攻撃アニメーションを行うために用いる
+	attacking            bool
+	attackDuration       int
+	originalX, originalY int
+
+	// 死亡してから経過したフレーム数
+	DeadFrame int
+
+	// 地面から浮いているかどうか
+	// 浮いている虫はバリケードを越えていき、radioTower の爆発も当たらない
+	Flying bool
+
+	// 甲羅がひっくり返っているかどうか (甲虫だけが使う)
+	// ひっくり返っている間はあらゆる攻撃が効く
+	Flipped bool
+
+	// かかっている状態異常
+	Statuses []*Status
+
+	// 種類ごとのふるまい
+	behavior BugBehavior
+
+	// 画像の拡大率。
+	// TODO: 本当は画像のサイズそのものを変更したほうが見た目も処理効率も良くなる。余裕があれば後々やろう。
+	Scale float64
+}
+
+func NewBug(kind BugKind, x, y int) *Bug {
+	spec := bugKindSpecOf(kind)
+	b := &Bug{
+		Kind: kind,
+
+		X: x,
+		Y: y,
+
+		// 大きさは bugs.png の各虫の切り出し範囲に拡大率をかけたもの
+		Width:  int(float64(spec.width) * spec.scale),
+		Height: int(float64(spec.height) * spec.scale),
+		Scale:  spec.scale,
+
+		Flying: spec.flying,
+
+		behavior: spec.newBehavior(),
+	}
+
+	s := BugStatsOf(kind)
+	b.Name = s.Name
+	b.Health = s.Health
+	b.MaxHealth = s.Health
+	b.Speed = s.Speed
+	b.AttackPower = s.AttackPower
+	b.AttackRange = s.AttackRange
+	b.AttackInterval = s.AttackInterval
+
+	return b
+}
+
+func (b *Bug) Position() (int, int) {
+	return b.X, b.Y
+}
+
+func (b *Bug) Size() (int, int) {
+	return b.Width, b.Height
+}
+
+func (b *Bug) Rect() Rect {
+	return centeredRect(b.X, b.Y, b.Width, b.Height)
+}
+
+func (b *Bug) IsDead() bool {
+	return b.Health <= 0
+}
+
+// Attacking は攻撃のために飛びかかっている最中かどうかを返す
+func (b *Bug) Attacking() bool {
+	return b.attacking
+}
+
+func (b *Bug) update(w *World) {
+	if b.Health <= 0 {
+		b.DeadFrame++
+		if b.DeadFrame >= DeadAnimationTotalFrame {
+			w.removeBug(b)
+		}
+		return
+	}
+
+	b.updateStatuses(w)
+	if b.IsDead() {
+		return
+	}
+	// 元に戻るまでの時間は気絶している間も進む
+	if r, ok := b.behavior.(recoveryTicker); ok {
+		r.tickRecovery(w, b)
+	}
+
+	// 気絶している間は動くことも攻撃することも、手下を呼ぶこともできない
+	if b.HasStatus(StatusStun) {
+		return
+	}
+
+	b.behavior.Tick(w, b)
+	if b.IsDead() {
+		return
+	}
+
+	target := b.behavior.Target(w, b)
+	if target == nil {
+		return
+	}
+
+	// 攻撃範囲に入っていれば攻撃し、そうでなければ近づく
+	if Intersects(b.attackRect(), target.Rect()) {
+		b.behavior.Attack(w, b, target)
+		return
+	}
+	b.behavior.Move(w, b, target)
+}
+
+// ranged は飛び道具での攻撃かどうか
+func (b *Bug) attack(w *World, target *Building, ranged bool) {
+	target.Damage(w, b.AttackPower)
+
+	w.Events.Publish(BugAttacked{Bug: b, Target: target, Ranged: ranged})
+}
+
+// DamageSource は虫にダメージを与えたもの
+type DamageSource int
+
+const (
+	DamageHand DamageSource = iota
+	DamageTower
+	DamageRadioTower
+	// やけどや毒などの状態異常
+	DamageStatus
+)
+
+// Damage は source からの攻撃で d のダメージを与える
+// 種類によっては攻撃の受け方が変わる (damageModifier を参照)
+// ダメージが入ったかどうかを返す。死んでいたり、攻撃が効かなかったりしたときは false
+func (b *Bug) Damage(w *World, d int, source DamageSource) bool {
+	if b.Health <= 0 {
+		return false
+	}
+
+	if m, ok := b.behavior.(damageModifier); ok {
+		d = m.modifyDamage(w, b, d, source)
+		if d <= 0 {
+			return false
+		}
+	}
+
+	b.Health -= d
+
+	if b.Health <= 0 {
+		b.Health = 0
+		w.Events.Publish(BugKilled{Bug: b})
+		b.behavior.OnDeath(w, b)
+	}
+	return true
+}
+
+// bugs は size + attackRange の範囲を当たり判定として用いる
+func (b *Bug) attackRect() Rect {
+	return Rect{
+		b.X - b.Width/2 - int(b.AttackRange), b.Y - b.Height/2 - int(b.AttackRange),
+		b.Width + int(b.AttackRange)*2, b.Height + int(b.AttackRange)*2,
+	}
+}
+
+// target に向かう途中に障害物が攻撃射程に入ったとき、その障害物を target とする
+// いずれかの建物が攻撃レンジに入っているか確認
+func (b *Bug) findBuildingInRange(w *World) *Building {
+	// 対象の建物と bugs の攻撃範囲を踏まえた当たり判定を行う
+	if building, ok := w.buildingIndex.findRect(b.attackRect()); ok {
+		// 攻撃射程圏内であるので、その建物を attack 対象にする
+		return building
+	}
+	return nil
+}
+
+// クールダウン中でなければ攻撃し、攻撃中であればアニメーション動作を行う
+func (b *Bug) attackWithLunge(w *World, target *Building) {
+	if b.attackCooldown <= 0 {
+		b.attack(w, target, false)
+		b.attackCooldown = b.AttackInterval
+
+		b.attacking = true
+		b.attackDuration = 7
+		b.originalX, b.originalY = b.X, b.Y
+	} else {
+		// クールダウンを消化する
+		b.attackCooldown -= 1
+	}
+
+	// 攻撃中であればアニメーション動作を行う
+	if b.attacking {
+		b.attackDuration--
+		if b.attackDuration <= 0 {
+			b.X = b.originalX
+			b.Y = b.originalY
+			b.attacking = false
+		} else {
+			// 攻撃対象に向かって一瞬スプライトを移動させる
+			targetX, targetY := target.Position()
+			dx := (targetX - b.X) / 4
+			dy := (targetY - b.Y) / 4
+			b.X += dx / b.attackDuration
+			b.Y += dy / b.attackDuration
+		}
+	}
+}
+
+// spawnAround は b のまわりに kind の虫を n 匹、等間隔に出す
+// 出した虫も World.Bugs に入るので、倒しきるまでウェーブは終わらない
+// ウェーブの中身が変わらないように World.Rand は使わない
+func (b *Bug) spawnAround(w *World, kind BugKind, n int) {
+	for i := 0; i < n; i++ {
+		angle := 2 * math.Pi * float64(i) / float64(n)
+		x := b.X + int(math.Cos(angle)*float64(b.Width))
+		y := b.Y + int(math.Sin(angle)*float64(b.Width))
+		w.AddBug(NewBug(kind, x, y))
+	}
+}
+
+// (x, y) に向かって移動する
+func (b *Bug) moveTowards(w *World, x, y int) {
+	// ターゲットへの直線距離を計算
+	dx := x - b.X
+	dy := y - b.Y
+
+	// 移動方向のラジアンを計算
+	angle := math.Atan2(float64(dy), float64(dx))
+
+	// 回避動作
+	// 虫同士がぴったり重ならないようにするための計算
+	// やや自信のないロジックではある
+	avoidX, avoidY := 0.0, 0.0
+	for _, ee := range w.bugIndex.queryRange(b.X, b.Y, float64(b.Width)) {
+		if ee != b {
+			distX := float64(ee.X - b.X)
+			distY := float64(ee.Y - b.Y)
+			distance := math.Sqrt(distX*distX + distY*distY)
+			if distance > 0 && distance < float64(b.Width) {
+				avoidX -= distX / distance
+				avoidY -= distY / distance
+			}
+		}
+	}
+
+	// 移動
+	// 鈍足などの状態異常で速さが変わる
+	speed := b.speed()
+	moveX := math.Cos(angle)*speed + avoidX
+	moveY := math.Sin(angle)*speed + avoidY
+	b.X += int(moveX)
+	b.Y += int(moveY)
+}
@@ -0,0 +1,15 @@
+package sim
+
+import "testing"
+
+// spawnNearHouse は新しい World を作り、家から右に離れたところに kind の虫を 1 匹置く
+// 虫のふるまいを確かめるテストで使う
+func spawnNearHouse(t *testing.T, kind BugKind) (*World, *Bug) {
+	t.Helper()
+
+	w := NewWorld(1)
+	hx, hy := w.House.Position()
+	b := NewBug(kind, hx+400, hy)
+	w.AddBug(b)
+	return w, b
+}
@@ -0,0 +1,57 @@
+package sim
+
+import "log"
+
+// bugKindSpec は虫の種類ごとの決まりごと
+// 新しい種類の虫を足すときは、その虫のファイルの init で registerBugKind を呼んで登録する
+type bugKindSpec struct {
+	// stats.json や waves.json の中での名前
+	name string
+	// 大きさ。bugs.png の切り出し範囲にあわせる
+	width, height int
+	// 画像の拡大率。0 のときは 1 とみなす
+	// 大きさも拡大率をかけたものになる
+	scale float64
+	// 地面から浮いているかどうか
+	flying bool
+	// ウェーブの戦闘力を計算するときの、この虫 1 匹の戦闘力
+	combatPower int
+	// 虫ひとりぶんのふるまいを作る関数
+	newBehavior func() BugBehavior
+}
+
+// 登録された虫の種類
+var bugKinds = map[BugKind]bugKindSpec{}
+
+func registerBugKind(kind BugKind, spec bugKindSpec) {
+	if _, ok := bugKinds[kind]; ok {
+		log.Fatalf("bug kind is already registered: %d", kind)
+	}
+	for k, s := range bugKinds {
+		if s.name == spec.name {
+			log.Fatalf("bug kind name %q is already used by %d", spec.name, k)
+		}
+	}
+	if spec.scale == 0 {
+		spec.scale = 1
+	}
+	bugKinds[kind] = spec
+}
+
+func bugKindSpecOf(kind BugKind) bugKindSpec {
+	spec, ok := bugKinds[kind]
+	if !ok {
+		log.Fatal("invalid bug kind")
+	}
+	return spec
+}
+
+// bugKindByName は stats.json や waves.json の中での名前から虫の種類を返す
+func bugKindByName(name string) (BugKind, bool) {
+	for kind, spec := range bugKinds {
+		if spec.name == name {
+			return kind, true
+		}
+	}
+	return 0, false
+}
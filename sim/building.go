@@ -0,0 +1,210 @@
+package sim
+
+import (
+	"log"
+)
+
+type BuildingKind int
+
+const (
+	BuildingHouse BuildingKind = iota
+	BuildingBarricade
+	BuildingTower
+	BuildingRadioTower
+)
+
+type Building struct {
+	ID   EntityID
+	Kind BuildingKind
+
+	// 中央の座標
+	X, Y int
+	// 当たり判定の大きさ。画像の拡大率を反映したもの
+	Width, Height int
+
+	// 以下は stats.json の値で初期化される
+	Health int
+
+	// 以下は攻撃する建物だけが使う
+	AttackPower int
+	// tower の射程
+	AttackRange float64
+	// radioTower の射程
+	// 近すぎる敵は攻撃できないので、最長攻撃可能距離と最短攻撃可能距離を持つ
+	ShortAttackRange float64
+	LongAttackRange  float64
+	AttackZoneRadius float64
+	// 攻撃してから次に攻撃できるまでのフレーム数
+	AttackInterval int
+	cooldown       int
+	// 攻撃が当たった虫にかける状態異常。nil のときはかけない
+	OnHit *StatusEffect
+
+	// 死亡してから経過したフレーム数
+	DeadFrame int
+}
+
+func NewBuilding(kind BuildingKind, x, y int) *Building {
+	b := &Building{
+		Kind: kind,
+		X:    x,
+		Y:    y,
+	}
+
+	// 大きさは各建物の画像 (と拡大率) にあわせている
+	switch kind {
+	case BuildingHouse:
+		b.Width, b.Height = 102, 102
+	case BuildingBarricade:
+		b.Width, b.Height = 103, 100
+	case BuildingTower:
+		b.Width, b.Height = 43, 105
+	case BuildingRadioTower:
+		b.Width, b.Height = 54, 104
+	default:
+		log.Fatal("invalid building kind")
+	}
+
+	s := BuildingStatsOf(kind)
+	b.Health = s.Health
+	b.AttackPower = s.AttackPower
+	b.AttackRange = s.AttackRange
+	b.ShortAttackRange = s.ShortAttackRange
+	b.LongAttackRange = s.LongAttackRange
+	b.AttackZoneRadius = s.AttackZoneRadius
+	b.AttackInterval = s.AttackInterval
+	b.OnHit = s.OnHit
+
+	return b
+}
+
+func (b *Building) Name() string {
+	return BuildingStatsOf(b.Kind).Name
+}
+
+// house は建築するものではないので 0
+func (b *Building) Cost() int {
+	return BuildingStatsOf(b.Kind).Cost
+}
+
+func (b *Building) Position() (int, int) {
+	return b.X, b.Y
+}
+
+func (b *Building) SetPosition(x, y int) {
+	b.X = x
+	b.Y = y
+}
+
+func (b *Building) Size() (int, int) {
+	return b.Width, b.Height
+}
+
+func (b *Building) Rect() Rect {
+	return centeredRect(b.X, b.Y, b.Width, b.Height)
+}
+
+func (b *Building) IsDead() bool {
+	return b.Health <= 0
+}
+
+func (b *Building) Damage(w *World, d int) {
+	if b.Health <= 0 {
+		return
+	}
+
+	b.Health -= d
+	w.Events.Publish(BuildingDamaged{Building: b, Damage: d})
+	if b.Health <= 0 {
+		b.Health = 0
+		w.Events.Publish(BuildingDestroyed{Building: b})
+	}
+}
+
+func (b *Building) update(w *World) {
+	// 死亡時のアニメーションを待ってから取り除く
+	if b.Health <= 0 {
+		b.DeadFrame++
+		if b.DeadFrame >= DeadAnimationTotalFrame {
+			w.removeBuilding(b)
+		}
+		return
+	}
+
+	switch b.Kind {
+	case BuildingTower:
+		towerUpdate(w, b)
+	case BuildingRadioTower:
+		radioTowerUpdate(w, b)
+	}
+}
+
+func towerUpdate(w *World, t *Building) {
+	// 家が壊れていたらもはや攻撃をやめる
+	if w.House.Health <= 0 {
+		return
+	}
+
+	// 敵が攻撃範囲に入ってきたら攻撃する
+	// 複数の敵が攻撃範囲に入ってきた場合は、最も近い敵を攻撃する
+
+	// 最寄りの敵を探す
+	nearestEnemy, _, ok := w.bugIndex.nearest(t.X, t.Y, t.AttackRange, nil)
+
+	// クールダウンが明けていて、かつ攻撃範囲に入っていれば攻撃する
+	if t.cooldown == 0 && ok {
+		w.Events.Publish(TowerFired{Tower: t, Target: nearestEnemy})
+
+		if nearestEnemy.Damage(w, t.AttackPower, DamageTower) {
+			t.applyOnHit(nearestEnemy)
+		}
+		t.cooldown = t.AttackInterval
+	}
+
+	if t.cooldown > 0 {
+		t.cooldown--
+	}
+}
+
+func radioTowerUpdate(w *World, t *Building) {
+	// 敵が攻撃範囲に入ってきたら攻撃する
+	// 複数の敵が攻撃範囲に入ってきた場合は、最も近い敵を攻撃する
+	// ただし近すぎる敵には攻撃できない
+	// 地面を狙う兵器なので、浮いている敵には当たらない
+
+	// shortAttackRange と longAttackRange の間にいる敵のうち、最も近い敵を探す
+	nearestEnemy, _, ok := w.bugIndex.nearest(t.X, t.Y, t.LongAttackRange, func(e *Bug, distance float64) bool {
+		// 敵が shortAttackRange と longAttackRange の間にいるかどうかを判定する
+		return !e.Flying && t.ShortAttackRange < distance
+	})
+
+	// クールダウンが明けていて、攻撃可能な敵がいる場合は攻撃する
+	if t.cooldown <= 0 && ok {
+		// nearestEnemy を中心に範囲攻撃を行う
+		ex, ey := nearestEnemy.Position()
+		w.Events.Publish(RadioTowerFired{Tower: t, X: ex, Y: ey})
+
+		for _, e := range w.bugIndex.queryRange(ex, ey, t.AttackZoneRadius) {
+			if e.Flying {
+				continue
+			}
+			if e.Damage(w, t.AttackPower, DamageRadioTower) {
+				t.applyOnHit(e)
+			}
+		}
+
+		t.cooldown = t.AttackInterval
+	}
+
+	if t.cooldown > 0 {
+		t.cooldown--
+	}
+}
+
+// applyOnHit は攻撃が当たった e に OnHit の状態異常をかける
+// 甲羅に弾かれたときなど、ダメージが入らなかったときは呼ばない
+func (b *Building) applyOnHit(e *Bug) {
+	if b.OnHit != nil {
+		e.ApplyStatus(*b.OnHit)
+	}
+}
@@ -0,0 +1,111 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Difficulty は難易度
+// 虫の強さは stats.json の値にかける倍率で、お金と家の体力はそのままの値で持つ
+type Difficulty struct {
+	Name string `json:"name"`
+
+	// 虫の体力、速さ、攻撃力にかける倍率
+	BugHealth float64 `json:"bugHealth"`
+	BugSpeed  float64 `json:"bugSpeed"`
+	BugDamage float64 `json:"bugDamage"`
+
+	// ゲーム開始時のクレジット
+	StartingCredit int `json:"startingCredit"`
+	// ウェーブを終えたときに得られるクレジット
+	WaveClearReward int `json:"waveClearReward"`
+	// 家の体力
+	HouseHealth int `json:"houseHealth"`
+}
+
+var (
+	DifficultyEasy = Difficulty{
+		Name:            "Easy",
+		BugHealth:       0.7,
+		BugSpeed:        0.8,
+		BugDamage:       1,
+		StartingCredit:  200,
+		WaveClearReward: 150,
+		HouseHealth:     150,
+	}
+	DifficultyNormal = Difficulty{
+		Name:            "Normal",
+		BugHealth:       1,
+		BugSpeed:        1,
+		BugDamage:       1,
+		StartingCredit:  100,
+		WaveClearReward: 120,
+		HouseHealth:     100,
+	}
+	DifficultyHard = Difficulty{
+		Name:            "Hard",
+		BugHealth:       1.5,
+		BugSpeed:        1.2,
+		BugDamage:       2,
+		StartingCredit:  50,
+		WaveClearReward: 100,
+		HouseHealth:     70,
+	}
+)
+
+// Validate は d がゲームとして成り立つ値かどうかを確かめる
+func (d Difficulty) Validate() error {
+	switch {
+	case d.Name == "":
+		return fmt.Errorf("name is missing")
+	case d.BugHealth <= 0:
+		return fmt.Errorf("invalid bug health: %v", d.BugHealth)
+	case d.BugSpeed <= 0:
+		return fmt.Errorf("invalid bug speed: %v", d.BugSpeed)
+	case d.BugDamage <= 0:
+		return fmt.Errorf("invalid bug damage: %v", d.BugDamage)
+	case d.StartingCredit < 0:
+		return fmt.Errorf("invalid starting credit: %d", d.StartingCredit)
+	case d.WaveClearReward < 0:
+		return fmt.Errorf("invalid wave clear reward: %d", d.WaveClearReward)
+	case d.HouseHealth <= 0:
+		return fmt.Errorf("invalid house health: %d", d.HouseHealth)
+	}
+	return nil
+}
+
+// ParseDifficulty は JSON で書かれた難易度を読む
+// 書かれていない値は base のものになる
+func ParseDifficulty(data []byte, base Difficulty) (Difficulty, error) {
+	d := base
+	if err := strictUnmarshal(data, &d); err != nil {
+		return base, err
+	}
+	if err := d.Validate(); err != nil {
+		return base, err
+	}
+	return d, nil
+}
+
+// MarshalDifficulty は ParseDifficulty で読める形に d を書き出す
+func MarshalDifficulty(d Difficulty) ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// applyTo は虫の能力値に難易度の倍率をかける
+// 体力と攻撃力は 1 より小さくならないようにする
+func (d Difficulty) applyTo(b *Bug) {
+	b.Health = max(1, int(math.Round(float64(b.Health)*d.BugHealth)))
+	b.MaxHealth = b.Health
+	b.Speed *= d.BugSpeed
+	b.AttackPower = max(1, int(math.Round(float64(b.AttackPower)*d.BugDamage)))
+}
+
+// SetDifficulty は難易度を決める
+// クレジットと家の体力は難易度のものになるので、最初のウェーブを始める前に呼ぶこと
+func (w *World) SetDifficulty(d Difficulty) {
+	w.Difficulty = d
+	w.Credit = d.StartingCredit
+	w.House.Health = d.HouseHealth
+}
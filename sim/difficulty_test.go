@@ -0,0 +1,65 @@
+package sim
+
+import "testing"
+
+func TestWorldDifficulty(t *testing.T) {
+	w := NewWorld(1)
+	w.SetDifficulty(DifficultyHard)
+
+	if w.Credit != DifficultyHard.StartingCredit {
+		t.Errorf("expected credit %d, got %d", DifficultyHard.StartingCredit, w.Credit)
+	}
+	if w.House.Health != DifficultyHard.HouseHealth {
+		t.Errorf("expected house health %d, got %d", DifficultyHard.HouseHealth, w.House.Health)
+	}
+
+	// 置かれた虫は難易度にあわせて強くなる
+	base := NewBug(BugBlue, 0, 0)
+	b := NewBug(BugBlue, 0, 0)
+	w.AddBug(b)
+	if b.Health <= base.Health || b.Speed <= base.Speed || b.AttackPower <= base.AttackPower {
+		t.Errorf("expected a stronger bug on hard, got %+v", b)
+	}
+
+	// ウェーブを終えたときのクレジットも難易度のもの
+	w.Bugs = nil
+	w.StartWave()
+	w.smallWave = len(w.waves[w.Wave])
+	w.checkWaveEnd()
+	if w.Credit != DifficultyHard.StartingCredit+DifficultyHard.WaveClearReward {
+		t.Errorf("expected reward %d, got credit %d", DifficultyHard.WaveClearReward, w.Credit)
+	}
+}
+
+func TestDifficultyEasyKeepsBugsAlive(t *testing.T) {
+	w := NewWorld(1)
+	w.SetDifficulty(Difficulty{Name: "Tiny", BugHealth: 0.01, BugSpeed: 1, BugDamage: 0.01, HouseHealth: 1})
+
+	b := NewBug(BugRed, 0, 0)
+	w.AddBug(b)
+	if b.Health != 1 || b.AttackPower != 1 {
+		t.Errorf("expected health and attack power not to go below 1, got %d, %d", b.Health, b.AttackPower)
+	}
+}
+
+func TestParseDifficulty(t *testing.T) {
+	d, err := ParseDifficulty([]byte(`{"name": "Custom", "houseHealth": 300}`), DifficultyNormal)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Name != "Custom" || d.HouseHealth != 300 || d.StartingCredit != DifficultyNormal.StartingCredit {
+		t.Errorf("expected only name and house health to change, got %+v", d)
+	}
+
+	for name, data := range map[string]string{
+		"unknown field": `{"credit": 10}`,
+		"bug health":    `{"bugHealth": 0}`,
+		"house health":  `{"houseHealth": -1}`,
+		"reward":        `{"waveClearReward": -1}`,
+		"syntax":        `{`,
+	} {
+		if _, err := ParseDifficulty([]byte(data), DifficultyNormal); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
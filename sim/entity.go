@@ -0,0 +1,12 @@
+package sim
+
+// EntityID は World に置かれた虫や建物を見分けるための ID
+// World に追加されたときに割り当てられ、取り除かれるまで変わらない
+// 0 はまだ World に追加されていないことを表す
+type EntityID int
+
+// 次に割り当てる ID を返す
+func (w *World) newEntityID() EntityID {
+	w.lastEntityID++
+	return w.lastEntityID
+}
@@ -0,0 +1,139 @@
+package sim
+
+// Event はシミュレーションの中で起きたことをあらわす
+// 音やエフェクト、統計などはイベントを購読して駆動する
+// (docs/README.md の「音を出す仕組み」を参照)
+type Event interface {
+	isEvent()
+}
+
+type BugSpawned struct{ Bug *Bug }
+type BugKilled struct{ Bug *Bug }
+
+// BugRemoved は死亡時のアニメーションを終えて取り除かれたときに発行される
+type BugRemoved struct{ Bug *Bug }
+
+// Ranged は飛び道具での攻撃のとき true になる
+type BugAttacked struct {
+	Bug    *Bug
+	Target *Building
+	Ranged bool
+}
+
+// BugExploded は自爆虫が (X, Y) を中心に Radius の範囲で爆発したときに発行される
+type BugExploded struct {
+	Bug    *Bug
+	X, Y   int
+	Radius float64
+}
+
+// BugFlipped は甲虫が叩かれて甲羅がひっくり返ったときに発行される
+type BugFlipped struct{ Bug *Bug }
+
+// BugHealed は回復虫が Target の体力を Amount だけ回復したときに発行される
+type BugHealed struct {
+	Healer *Bug
+	Target *Bug
+	Amount int
+}
+
+type BuildingPlaced struct{ Building *Building }
+type BuildingDamaged struct {
+	Building *Building
+	Damage   int
+}
+type BuildingDestroyed struct{ Building *Building }
+
+// BuildingRemoved は死亡時のアニメーションを終えて取り除かれたときに発行される
+type BuildingRemoved struct{ Building *Building }
+
+type TowerFired struct {
+	Tower  *Building
+	Target *Bug
+}
+
+// RadioTowerFired の X, Y は範囲攻撃の中心
+type RadioTowerFired struct {
+	Tower *Building
+	X, Y  int
+}
+type HandSlapped struct{ Hand *Hand }
+
+// BossPhaseChanged はボスの体力が減って次の段階に移ったときに発行される
+type BossPhaseChanged struct {
+	Bug   *Bug
+	Phase BossPhase
+}
+
+// Wave はウェーブの番号 (0 はじまり)
+type WaveStarted struct{ Wave int }
+type WaveCleared struct{ Wave int }
+type AllCleared struct{}
+type GameOver struct{}
+
+func (BugSpawned) isEvent()        {}
+func (BugKilled) isEvent()         {}
+func (BugRemoved) isEvent()        {}
+func (BugAttacked) isEvent()       {}
+func (BugHealed) isEvent()         {}
+func (BugExploded) isEvent()       {}
+func (BugFlipped) isEvent()        {}
+func (BuildingPlaced) isEvent()    {}
+func (BuildingDamaged) isEvent()   {}
+func (BuildingDestroyed) isEvent() {}
+func (BuildingRemoved) isEvent()   {}
+func (TowerFired) isEvent()        {}
+func (RadioTowerFired) isEvent()   {}
+func (HandSlapped) isEvent()       {}
+func (BossPhaseChanged) isEvent()  {}
+func (WaveStarted) isEvent()       {}
+func (WaveCleared) isEvent()       {}
+func (AllCleared) isEvent()        {}
+func (GameOver) isEvent()          {}
+
+// EventBus は発行されたイベントをためておき、Dispatch のときにまとめて購読者に配る
+// ゲームロジックは発行した時点では誰が購読しているかを気にしなくてよい
+type EventBus struct {
+	queue       []Event
+	subscribers []func(Event)
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Publish はイベントをキューに積む
+// 購読者がいなければ積まずに捨てる
+func (b *EventBus) Publish(e Event) {
+	if len(b.subscribers) == 0 {
+		return
+	}
+	b.queue = append(b.queue, e)
+}
+
+// Subscribe はすべてのイベントを受け取る購読者を登録する
+func (b *EventBus) Subscribe(fn func(Event)) {
+	b.subscribers = append(b.subscribers, fn)
+}
+
+// Subscribe は T 型のイベントだけを受け取る購読者を登録する
+func Subscribe[T Event](b *EventBus, fn func(T)) {
+	b.Subscribe(func(e Event) {
+		if e, ok := e.(T); ok {
+			fn(e)
+		}
+	})
+}
+
+// Dispatch はためておいたイベントを発行された順に購読者に配る
+// 配っている最中に発行されたイベントも、同じ Dispatch の中で後ろに続けて配る
+func (b *EventBus) Dispatch() {
+	for len(b.queue) > 0 {
+		e := b.queue[0]
+		b.queue = b.queue[1:]
+		for _, fn := range b.subscribers {
+			fn(e)
+		}
+	}
+	b.queue = nil
+}
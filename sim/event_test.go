@@ -0,0 +1,90 @@
+package sim
+
+import (
+	"testing"
+)
+
+func TestEventBusDispatch(t *testing.T) {
+	bus := NewEventBus()
+
+	var all []Event
+	var killed []*Bug
+	bus.Subscribe(func(e Event) { all = append(all, e) })
+	Subscribe(bus, func(e BugKilled) { killed = append(killed, e.Bug) })
+
+	b := NewBug(BugRed, 0, 0)
+	bus.Publish(BugSpawned{Bug: b})
+	bus.Publish(BugKilled{Bug: b})
+
+	// Dispatch されるまでは配られない
+	if len(all) != 0 {
+		t.Fatalf("expected no events before dispatch, got %d", len(all))
+	}
+
+	bus.Dispatch()
+
+	if len(all) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(all))
+	}
+	if _, ok := all[0].(BugSpawned); !ok {
+		t.Errorf("expected events in published order, got %T first", all[0])
+	}
+	if len(killed) != 1 || killed[0] != b {
+		t.Errorf("expected typed subscriber to receive only BugKilled")
+	}
+
+	// 一度配ったイベントは二度と配られない
+	bus.Dispatch()
+	if len(all) != 2 {
+		t.Errorf("expected no events after the queue is drained, got %d", len(all))
+	}
+}
+
+func TestEventBusPublishDuringDispatch(t *testing.T) {
+	bus := NewEventBus()
+
+	var cleared, allCleared int
+	Subscribe(bus, func(e WaveCleared) {
+		cleared++
+		bus.Publish(AllCleared{})
+	})
+	Subscribe(bus, func(AllCleared) { allCleared++ })
+
+	bus.Publish(WaveCleared{Wave: 0})
+	bus.Dispatch()
+
+	if cleared != 1 || allCleared != 1 {
+		t.Errorf("expected events published during dispatch to be delivered in the same dispatch, got %d, %d", cleared, allCleared)
+	}
+}
+
+func TestEventBusWithoutSubscribers(t *testing.T) {
+	bus := NewEventBus()
+	bus.Publish(GameOver{})
+
+	if len(bus.queue) != 0 {
+		t.Errorf("expected events without subscribers to be dropped")
+	}
+}
+
+func TestWorldEvents(t *testing.T) {
+	w := NewWorld(1)
+
+	var events []Event
+	w.Events.Subscribe(func(e Event) { events = append(events, e) })
+
+	hx, hy := w.House.Position()
+	w.Build(NewBuilding(BuildingBarricade, hx+300, hy))
+	w.StartWave()
+	w.Events.Dispatch()
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if _, ok := events[0].(BuildingPlaced); !ok {
+		t.Errorf("expected BuildingPlaced, got %T", events[0])
+	}
+	if e, ok := events[1].(WaveStarted); !ok || e.Wave != 0 {
+		t.Errorf("expected WaveStarted for wave 0, got %#v", events[1])
+	}
+}
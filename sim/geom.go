@@ -0,0 +1,24 @@
+package sim
+
+import "math"
+
+// Rect は左上の座標と大きさであらわす矩形
+type Rect struct {
+	X, Y, Width, Height int
+}
+
+// 中心座標と大きさから Rect を作る
+func centeredRect(x, y, width, height int) Rect {
+	return Rect{x - width/2, y - height/2, width, height}
+}
+
+func Intersects(r1, r2 Rect) bool {
+	return r1.X < r2.X+r2.Width &&
+		r2.X < r1.X+r1.Width &&
+		r1.Y < r2.Y+r2.Height &&
+		r2.Y < r1.Y+r1.Height
+}
+
+func distance(x1, y1, x2, y2 int) float64 {
+	return math.Sqrt(math.Pow(float64(x1-x2), 2) + math.Pow(float64(y1-y2), 2))
+}
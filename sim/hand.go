@@ -0,0 +1,77 @@
+package sim
+
+// プレイヤーが虫を叩くための手
+// 同時に存在する手はひとつである
+type Hand struct {
+	X, Y          int
+	Width, Height int
+
+	// 表示している時間
+	// これが 0 になったら消える
+	displayTime int
+
+	cooldown    int
+	erapsedTime int // 攻撃実行からの経過時間
+
+	AttackPower int
+}
+
+func newHand() *Hand {
+	return &Hand{
+		// 大きさは hand_small.png にあわせている
+		Width:  53,
+		Height: 55,
+
+		cooldown: 10, // ここを短くすると連打できるようになっていく
+
+		AttackPower: 3,
+	}
+}
+
+// Visible は手を表示しておくべきかどうかを返す
+func (h *Hand) Visible() bool {
+	return h.displayTime > 0
+}
+
+func (h *Hand) Rect() Rect {
+	return centeredRect(h.X, h.Y, h.Width, h.Height)
+}
+
+// Slap は (x, y) を叩く
+// cooldown があけていなかったら叩かずに false を返す
+func (w *World) Slap(x, y int) bool {
+	h := w.Hand
+
+	// cooldown があけていなかったら攻撃を発動しない
+	if h.erapsedTime != 0 && h.erapsedTime < h.cooldown {
+		return false
+	}
+
+	h.X = x
+	h.Y = y
+
+	// 見た目とクールダウンを一致させているが、かならずしもそうではないかも
+	h.displayTime = h.cooldown
+	h.erapsedTime = 0
+
+	return true
+}
+
+func (h *Hand) update(w *World) {
+	if !h.Visible() {
+		return
+	}
+
+	h.erapsedTime++
+	h.displayTime--
+
+	// クリックから 5 フレーム後に攻撃を実行する
+	if h.erapsedTime == 5 {
+		w.Events.Publish(HandSlapped{Hand: h})
+
+		// 攻撃範囲内にいる敵に対してダメージを与える
+		for _, e := range w.bugIndex.queryRect(h.Rect()) {
+			e.Damage(w, h.AttackPower, DamageHand)
+		}
+	}
+}
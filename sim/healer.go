@@ -0,0 +1,85 @@
+package sim
+
+import "math"
+
+const (
+	// 回復する間隔 (フレーム数)
+	healerHealInterval = 120
+	// 一度に回復する量と、回復が届く範囲
+	healerHealAmount = 2
+	healerHealRadius = 120.0
+	// 前を行く虫からこれだけ離れてついていく
+	healerFollowDistance = 80.0
+)
+
+// 見た目は赤虫の色違い
+func init() {
+	registerBugKind(BugHealer, bugKindSpec{
+		name:        "healer",
+		width:       28,
+		height:      40,
+		combatPower: 3,
+		newBehavior: func() BugBehavior { return &healerBugBehavior{} },
+	})
+}
+
+// 回復虫の特徴
+// 群れの後ろについていき、一定時間ごとにまわりの虫の体力を回復する。
+// 自分は回復しない。体力は最大値までしか回復しない。
+// ついていく虫がいなくなったら家に向かう。
+// 群れの後ろにいるので、先に倒すには叩くか射程の長い兵器で狙う必要がある。
+type healerBugBehavior struct {
+	houseBound
+
+	healCooldown int
+}
+
+func (h *healerBugBehavior) Tick(w *World, b *Bug) {
+	if h.healCooldown > 0 {
+		h.healCooldown--
+		return
+	}
+
+	healed := false
+	for _, e := range w.bugIndex.queryRange(b.X, b.Y, healerHealRadius) {
+		if e == b || e.IsDead() || e.Health >= e.MaxHealth {
+			continue
+		}
+		amount := min(healerHealAmount, e.MaxHealth-e.Health)
+		e.Health += amount
+		w.Events.Publish(BugHealed{Healer: b, Target: e, Amount: amount})
+		healed = true
+	}
+
+	// 誰も回復しなかったときは、次のフレームにまた探す
+	if healed {
+		h.healCooldown = healerHealInterval
+	}
+}
+
+func (h *healerBugBehavior) Move(w *World, b *Bug, target *Building) {
+	leader, ok := h.leader(w, b)
+	if !ok {
+		h.houseBound.Move(w, b, target)
+		return
+	}
+
+	// 前を行く虫に近づきすぎないようにする
+	if distance(b.X, b.Y, leader.X, leader.Y) <= healerFollowDistance {
+		return
+	}
+	b.moveTowards(w, leader.X, leader.Y)
+}
+
+// leader はついていく虫を返す
+// 回復虫以外で生きている、いちばん近い虫についていく
+func (h *healerBugBehavior) leader(w *World, b *Bug) (*Bug, bool) {
+	leader, _, ok := w.bugIndex.nearest(b.X, b.Y, math.MaxFloat64, func(e *Bug, _ float64) bool {
+		return e.Kind != BugHealer && !e.IsDead()
+	})
+	return leader, ok
+}
+
+func (h *healerBugBehavior) Attack(w *World, b *Bug, target *Building) {
+	b.attackWithLunge(w, target)
+}
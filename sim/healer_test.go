@@ -0,0 +1,46 @@
+package sim
+
+import "testing"
+
+func TestHealerHealsNearbyBugs(t *testing.T) {
+	w, healer := spawnNearHouse(t, BugHealer)
+	var healed []BugHealed
+	Subscribe(w.Events, func(e BugHealed) { healed = append(healed, e) })
+
+	near := NewBug(BugBlue, healer.X+50, healer.Y)
+	w.AddBug(near)
+	far := NewBug(BugBlue, healer.X+int(healerHealRadius)+50, healer.Y)
+	w.AddBug(far)
+
+	near.Damage(w, 3, DamageHand)
+	far.Damage(w, 3, DamageHand)
+	healer.Damage(w, 1, DamageHand)
+	healer.update(w)
+
+	if near.Health != near.MaxHealth-3+healerHealAmount {
+		t.Errorf("expected the nearby bug to be healed, got %d", near.Health)
+	}
+	if far.Health != far.MaxHealth-3 {
+		t.Errorf("expected the far bug not to be healed, got %d", far.Health)
+	}
+	if healer.Health != healer.MaxHealth-1 {
+		t.Errorf("expected the healer not to heal itself, got %d", healer.Health)
+	}
+
+	// 間隔をあけるまでは回復しない。回復しても最大値を超えない
+	for i := 0; i < healerHealInterval; i++ {
+		healer.update(w)
+	}
+	if near.Health != near.MaxHealth-3+healerHealAmount {
+		t.Errorf("expected no heal during the cooldown, got %d", near.Health)
+	}
+	healer.update(w)
+	if near.Health != near.MaxHealth {
+		t.Errorf("expected the nearby bug to be healed up to %d, got %d", near.MaxHealth, near.Health)
+	}
+
+	w.Events.Dispatch()
+	if len(healed) != 2 || healed[0].Target != near || healed[1].Amount != 1 {
+		t.Errorf("unexpected heal events: %+v", healed)
+	}
+}
@@ -0,0 +1,45 @@
+package sim
+
+import "math"
+
+// 爆発が届く範囲
+// 建物の中心がこの範囲に入っていれば巻き込まれる
+const kamikazeBlastRadius = 120.0
+
+// 見た目は青虫の色違い
+func init() {
+	registerBugKind(BugKamikaze, bugKindSpec{
+		name:        "kamikaze",
+		width:       29,
+		height:      41,
+		combatPower: 3,
+		newBehavior: func() BugBehavior { return kamikazeBugBehavior{} },
+	})
+}
+
+// 自爆虫の特徴
+// 最寄りの建物に向かって突っ込み、ぶつかったら自爆する。
+// 爆発はまわりの建物すべてに AttackPower のダメージを与える。
+// 体力は少ないので、たどり着く前に叩けば爆発させずに倒せる。
+type kamikazeBugBehavior struct{ houseBound }
+
+func (kamikazeBugBehavior) Target(w *World, b *Bug) *Building {
+	nearestBuilding, _, ok := w.buildingIndex.nearest(b.X, b.Y, math.MaxFloat64, nil)
+	if !ok {
+		// すべての建物が破壊されている場合はその場にとどまる
+		return nil
+	}
+	return nearestBuilding
+}
+
+func (kamikazeBugBehavior) Attack(w *World, b *Bug, target *Building) {
+	w.Events.Publish(BugExploded{Bug: b, X: b.X, Y: b.Y, Radius: kamikazeBlastRadius})
+
+	// 更新中に取り除かれるものがあるのでコピーしてから回す
+	for _, building := range append([]*Building{}, w.buildingIndex.queryRange(b.X, b.Y, kamikazeBlastRadius)...) {
+		building.Damage(w, b.AttackPower)
+	}
+
+	// 自爆はプレイヤーが倒したわけではないので BugKilled は発行しない
+	b.Health = 0
+}
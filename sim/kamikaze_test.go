@@ -0,0 +1,54 @@
+package sim
+
+import "testing"
+
+func TestKamikazeExplodes(t *testing.T) {
+	w, b := spawnNearHouse(t, BugKamikaze)
+	var exploded, killed int
+	Subscribe(w.Events, func(BugExploded) { exploded++ })
+	Subscribe(w.Events, func(BugKilled) { killed++ })
+
+	// 最寄りのバリケードのすぐ右隣にいる
+	near := NewBuilding(BuildingBarricade, 0, b.Y)
+	near.X = b.X - near.Width/2 - 15
+	w.AddBuilding(near)
+	far := NewBuilding(BuildingBarricade, near.X, near.Y+300)
+	w.AddBuilding(far)
+
+	b.update(w)
+
+	if !b.IsDead() {
+		t.Errorf("expected the kamikaze bug to die in the explosion")
+	}
+	power := b.AttackPower
+	if near.Health != BuildingStatsOf(BuildingBarricade).Health-power {
+		t.Errorf("expected the nearby barricade to be damaged by %d, got health %d", power, near.Health)
+	}
+	if far.Health != BuildingStatsOf(BuildingBarricade).Health {
+		t.Errorf("expected the far barricade not to be damaged, got health %d", far.Health)
+	}
+
+	w.Events.Dispatch()
+	if exploded != 1 || killed != 0 {
+		t.Errorf("expected 1 explosion and no kill, got %d and %d", exploded, killed)
+	}
+}
+
+func TestKamikazeSlappedBeforeArrival(t *testing.T) {
+	w, b := spawnNearHouse(t, BugKamikaze)
+	var exploded int
+	Subscribe(w.Events, func(BugExploded) { exploded++ })
+
+	b.Damage(w, b.Health, DamageHand)
+	for i := 0; i < DeadAnimationTotalFrame; i++ {
+		b.update(w)
+	}
+
+	w.Events.Dispatch()
+	if exploded != 0 {
+		t.Errorf("expected a killed kamikaze bug not to explode")
+	}
+	if w.House.Health != w.Difficulty.HouseHealth {
+		t.Errorf("expected the house not to be damaged, got %d", w.House.Health)
+	}
+}
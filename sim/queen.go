@@ -0,0 +1,45 @@
+package sim
+
+const (
+	// 女王虫の画像の拡大率
+	queenScale = 2.0
+	// 赤虫を産む間隔 (フレーム数) と、一度に産む数
+	queenSpawnInterval = 240
+	queenSpawnCount    = 2
+)
+
+// 見た目は赤虫を大きくしたもの
+func init() {
+	registerBugKind(BugQueen, bugKindSpec{
+		name:        "queen",
+		width:       28,
+		height:      40,
+		scale:       queenScale,
+		combatPower: 10,
+		newBehavior: func() BugBehavior { return &queenBugBehavior{} },
+	})
+}
+
+// 女王虫の特徴
+// 家に向かってゆっくり進む。体力がとても多い。
+// 生きている間、一定時間ごとにまわりに赤虫を産む。早く倒すほど敵が増えずにすむ。
+type queenBugBehavior struct {
+	houseBound
+
+	// 最後に産んでから経過したフレーム数
+	spawnFrame int
+}
+
+func (q *queenBugBehavior) Tick(w *World, b *Bug) {
+	// 出てきてすぐには産まない
+	q.spawnFrame++
+	if q.spawnFrame < queenSpawnInterval {
+		return
+	}
+	q.spawnFrame = 0
+	b.spawnAround(w, BugRed, queenSpawnCount)
+}
+
+func (q *queenBugBehavior) Attack(w *World, b *Bug, target *Building) {
+	b.attackWithLunge(w, target)
+}
@@ -0,0 +1,82 @@
+package sim
+
+import "testing"
+
+func TestQueenSpawnsRedBugs(t *testing.T) {
+	w, queen := spawnNearHouse(t, BugQueen)
+
+	for i := 0; i < queenSpawnInterval; i++ {
+		queen.update(w)
+	}
+	if len(w.Bugs) != 1+queenSpawnCount {
+		t.Fatalf("expected %d red bugs to be spawned, got %d bugs", queenSpawnCount, len(w.Bugs))
+	}
+	for _, b := range w.Bugs[1:] {
+		if b.Kind != BugRed {
+			t.Errorf("expected spawned bugs to be red, got %d", b.Kind)
+		}
+	}
+
+	// 倒されたらもう産まない
+	queen.Damage(w, queen.Health, DamageHand)
+	for i := 0; i < queenSpawnInterval; i++ {
+		queen.update(w)
+	}
+	if len(w.Bugs) > 1+queenSpawnCount {
+		t.Errorf("expected a dead queen not to spawn, got %d bugs", len(w.Bugs))
+	}
+}
+
+func TestQueenWaveEndsAfterSpawnedBugs(t *testing.T) {
+	w := NewWorld(1)
+	w.waves = [][]smallWave{{{spawnFrame: 0, spawnInfoList: []spawnInfo{{BugQueen, ScreenWidth / 2, -50}}}}}
+
+	var cleared bool
+	Subscribe(w.Events, func(WaveCleared) { cleared = true })
+	w.StartWave()
+	w.Step()
+
+	// 産まれた虫が残っている間はウェーブが終わらない
+	queen := w.Bugs[0]
+	for i := 0; i < queenSpawnInterval; i++ {
+		w.Step()
+	}
+	queen.Damage(w, queen.Health, DamageHand)
+	for i := 0; i < DeadAnimationTotalFrame+1; i++ {
+		w.Step()
+	}
+	w.Events.Dispatch()
+	if cleared || !w.WaveRunning() {
+		t.Fatalf("expected the wave to go on while spawned bugs are alive")
+	}
+
+	for _, b := range w.Bugs {
+		b.Damage(w, b.Health, DamageHand)
+	}
+	for i := 0; i < DeadAnimationTotalFrame+1; i++ {
+		w.Step()
+	}
+	w.Events.Dispatch()
+	if !cleared {
+		t.Errorf("expected the wave to be cleared")
+	}
+}
+
+func TestQueenWalksIntoField(t *testing.T) {
+	w := NewWorld(1)
+	// いちばん遅くなる難易度にする
+	w.SetDifficulty(DifficultyEasy)
+
+	// 下の端から、家とは斜めの向きに出てくる
+	// 速さが 1 のときは、縦も横も 1 ドットに満たずに止まっていた
+	queen := NewBug(BugQueen, 200, ScreenHeight+50)
+	w.AddBug(queen)
+	for i := 0; i < 600; i++ {
+		queen.update(w)
+		w.bugIndex.update(queen)
+	}
+
+	if queen.X <= 200 || queen.Y >= FieldHeight {
+		t.Errorf("expected the queen to walk into the field, got (%d, %d)", queen.X, queen.Y)
+	}
+}
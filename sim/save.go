@@ -0,0 +1,112 @@
+package sim
+
+import "fmt"
+
+// セーブデータの形式のバージョン
+// 形式を変えたら上げる
+const SnapshotVersion = 2
+
+// Snapshot は建築フェーズが始まったときのゲームの状態
+// ウェーブの中身はシードから作りなおせるので、シードと次のウェーブの番号だけを持つ
+type Snapshot struct {
+	Version int   `json:"version"`
+	Seed    int64 `json:"seed"`
+	Endless bool  `json:"endless"`
+
+	Difficulty Difficulty `json:"difficulty"`
+
+	// 次に始まるウェーブの番号
+	Wave   int `json:"wave"`
+	Credit int `json:"credit"`
+
+	HouseHealth int `json:"houseHealth"`
+
+	// 家以外の建物
+	Buildings []BuildingSnapshot `json:"buildings"`
+}
+
+type BuildingSnapshot struct {
+	Kind   BuildingKind `json:"kind"`
+	X      int          `json:"x"`
+	Y      int          `json:"y"`
+	Health int          `json:"health"`
+}
+
+// Snapshot はいまの状態を Snapshot にする
+// ウェーブの途中の状態 (虫など) は含まないので、建築フェーズの間に呼ぶこと
+func (w *World) Snapshot() Snapshot {
+	s := Snapshot{
+		Version:     SnapshotVersion,
+		Seed:        w.Seed,
+		Endless:     w.Endless,
+		Difficulty:  w.Difficulty,
+		Wave:        w.Wave,
+		Credit:      w.Credit,
+		HouseHealth: w.House.Health,
+	}
+	for _, b := range w.Buildings {
+		// 壊れて取り除かれるのを待っているものは保存しない
+		if b == w.House || b.IsDead() {
+			continue
+		}
+		s.Buildings = append(s.Buildings, BuildingSnapshot{
+			Kind:   b.Kind,
+			X:      b.X,
+			Y:      b.Y,
+			Health: b.Health,
+		})
+	}
+	return s
+}
+
+// RestoreWorld は s の状態から World を作る
+// s がおかしな値を持っているときはエラーを返す
+func RestoreWorld(s Snapshot) (*World, error) {
+	if s.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version: %d", s.Version)
+	}
+
+	if err := s.Difficulty.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid difficulty: %w", err)
+	}
+
+	w := NewWorld(s.Seed)
+	w.Endless = s.Endless
+	w.SetDifficulty(s.Difficulty)
+
+	// エンドレスモードではウェーブの数に上限がない
+	if s.Wave < 0 || (!s.Endless && s.Wave >= w.WaveCount()) {
+		return nil, fmt.Errorf("invalid wave: %d", s.Wave)
+	}
+	if s.Credit < 0 {
+		return nil, fmt.Errorf("invalid credit: %d", s.Credit)
+	}
+	if s.HouseHealth <= 0 || s.HouseHealth > w.House.Health {
+		return nil, fmt.Errorf("invalid house health: %d", s.HouseHealth)
+	}
+
+	w.Wave = s.Wave
+	w.Credit = s.Credit
+	w.House.Health = s.HouseHealth
+
+	for _, bs := range s.Buildings {
+		switch bs.Kind {
+		case BuildingBarricade, BuildingTower, BuildingRadioTower:
+		default:
+			return nil, fmt.Errorf("invalid building kind: %d", bs.Kind)
+		}
+
+		b := NewBuilding(bs.Kind, bs.X, bs.Y)
+		if bs.Health <= 0 || bs.Health > b.Health {
+			return nil, fmt.Errorf("invalid health for %s: %d", b.Name(), bs.Health)
+		}
+		b.Health = bs.Health
+
+		if w.Overlaps(b) {
+			return nil, fmt.Errorf("%s at (%d, %d) overlaps another building", b.Name(), bs.X, bs.Y)
+		}
+		w.AddBuilding(b)
+	}
+
+	return w, nil
+}
@@ -0,0 +1,64 @@
+package sim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	w := NewWorld(7)
+	w.Wave = 3
+	w.Credit = 250
+	w.House.Health = 40
+
+	tower := NewBuilding(BuildingTower, 200, 200)
+	w.Build(tower)
+	tower.Health = 10
+
+	s := w.Snapshot()
+	restored, err := RestoreWorld(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(restored.Snapshot(), s) {
+		t.Errorf("expected %+v, got %+v", s, restored.Snapshot())
+	}
+	if len(restored.Buildings) != 2 {
+		t.Errorf("expected house and tower, got %d buildings", len(restored.Buildings))
+	}
+
+	// 同じシードなのでウェーブの中身も同じになる
+	if !reflect.DeepEqual(restored.waves, w.waves) {
+		t.Errorf("expected the same waves after restore")
+	}
+}
+
+func TestRestoreWorldInvalid(t *testing.T) {
+	valid := NewWorld(1).Snapshot()
+
+	for name, modify := range map[string]func(s *Snapshot){
+		"version":      func(s *Snapshot) { s.Version = 0 },
+		"wave":         func(s *Snapshot) { s.Wave = 100 },
+		"credit":       func(s *Snapshot) { s.Credit = -1 },
+		"house health": func(s *Snapshot) { s.HouseHealth = 0 },
+		"kind": func(s *Snapshot) {
+			s.Buildings = []BuildingSnapshot{{Kind: BuildingHouse, X: 100, Y: 100, Health: 10}}
+		},
+		"health": func(s *Snapshot) {
+			s.Buildings = []BuildingSnapshot{{Kind: BuildingTower, X: 100, Y: 100, Health: 1000}}
+		},
+		"overlap": func(s *Snapshot) {
+			s.Buildings = []BuildingSnapshot{
+				{Kind: BuildingTower, X: 100, Y: 100, Health: 10},
+				{Kind: BuildingTower, X: 100, Y: 100, Health: 10},
+			}
+		},
+	} {
+		s := valid
+		modify(&s)
+		if _, err := RestoreWorld(s); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package sim
+
+import "math"
+
+// 空間インデックスの 1 マスの大きさ
+// 虫の大きさ (30 前後) より少し大きくしておく
+const spatialCellSize = 64
+
+// spatialItem は空間インデックスに登録できるもの
+// Position で返す中心座標によってマスに振り分ける
+type spatialItem interface {
+	comparable
+	Position() (int, int)
+	Rect() Rect
+}
+
+type cellKey struct {
+	x, y int
+}
+
+// spatialGrid は一様なグリッドによる空間インデックス
+// 敵や建物を座標で探すときに、すべてを調べずに近くのマスだけを調べるために使う
+// 登録されたものが動いたときは update を呼んでマスを付け替える
+type spatialGrid[T spatialItem] struct {
+	cells map[cellKey][]T
+	// 登録されたものがどのマスにいるか
+	where map[T]cellKey
+
+	// 登録されたものの大きさの半分の最大値
+	// Rect での検索のときに、中心がマスの外にあっても Rect がはみ出してくるものを拾うために使う
+	maxHalfWidth, maxHalfHeight int
+}
+
+func newSpatialGrid[T spatialItem]() *spatialGrid[T] {
+	return &spatialGrid[T]{
+		cells: map[cellKey][]T{},
+		where: map[T]cellKey{},
+	}
+}
+
+func cellOf(x, y int) cellKey {
+	return cellKey{floorDiv(x, spatialCellSize), floorDiv(y, spatialCellSize)}
+}
+
+// 負の座標でもマスがずれないように切り捨てで割る
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
+
+func (g *spatialGrid[T]) len() int {
+	return len(g.where)
+}
+
+func (g *spatialGrid[T]) insert(item T) {
+	if _, ok := g.where[item]; ok {
+		g.update(item)
+		return
+	}
+
+	k := cellOf(item.Position())
+	g.cells[k] = append(g.cells[k], item)
+	g.where[item] = k
+
+	r := item.Rect()
+	g.maxHalfWidth = max(g.maxHalfWidth, (r.Width+1)/2)
+	g.maxHalfHeight = max(g.maxHalfHeight, (r.Height+1)/2)
+}
+
+func (g *spatialGrid[T]) remove(item T) {
+	k, ok := g.where[item]
+	if !ok {
+		return
+	}
+	delete(g.where, item)
+	g.removeFromCell(k, item)
+}
+
+func (g *spatialGrid[T]) removeFromCell(k cellKey, item T) {
+	cell := g.cells[k]
+	for i, v := range cell {
+		if v == item {
+			cell = append(cell[:i], cell[i+1:]...)
+			break
+		}
+	}
+	if len(cell) == 0 {
+		delete(g.cells, k)
+		return
+	}
+	g.cells[k] = cell
+}
+
+// update は item が動いたあとに呼び、必要ならマスを付け替える
+func (g *spatialGrid[T]) update(item T) {
+	old, ok := g.where[item]
+	if !ok {
+		return
+	}
+	k := cellOf(item.Position())
+	if k == old {
+		return
+	}
+	g.removeFromCell(old, item)
+	g.cells[k] = append(g.cells[k], item)
+	g.where[item] = k
+}
+
+// 左上のマスから右下のマスまでを順番に調べる
+// fn が false を返したらそこでやめる
+func (g *spatialGrid[T]) eachInCells(topLeft, bottomRight cellKey, fn func(T) bool) {
+	for y := topLeft.y; y <= bottomRight.y; y++ {
+		for x := topLeft.x; x <= bottomRight.x; x++ {
+			for _, item := range g.cells[cellKey{x, y}] {
+				if !fn(item) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// queryRange は (x, y) からの距離が r 未満のものを返す
+func (g *spatialGrid[T]) queryRange(x, y int, r float64) []T {
+	var ret []T
+	ir := int(math.Ceil(r))
+	g.eachInCells(cellOf(x-ir, y-ir), cellOf(x+ir, y+ir), func(item T) bool {
+		ix, iy := item.Position()
+		if distance(x, y, ix, iy) < r {
+			ret = append(ret, item)
+		}
+		return true
+	})
+	return ret
+}
+
+// queryRect は Rect が rect と重なっているものを返す
+func (g *spatialGrid[T]) queryRect(rect Rect) []T {
+	var ret []T
+	topLeft, bottomRight := g.rectCells(rect)
+	g.eachInCells(topLeft, bottomRight, func(item T) bool {
+		if Intersects(rect, item.Rect()) {
+			ret = append(ret, item)
+		}
+		return true
+	})
+	return ret
+}
+
+// rect と重なっているものがいる可能性のあるマスの範囲を返す
+func (g *spatialGrid[T]) rectCells(rect Rect) (cellKey, cellKey) {
+	return cellOf(rect.X-g.maxHalfWidth, rect.Y-g.maxHalfHeight),
+		cellOf(rect.X+rect.Width+g.maxHalfWidth, rect.Y+rect.Height+g.maxHalfHeight)
+}
+
+// findRect は Rect が rect と重なっているもののうち、最初に見つかったものを返す
+func (g *spatialGrid[T]) findRect(rect Rect) (T, bool) {
+	var found T
+	var ok bool
+	topLeft, bottomRight := g.rectCells(rect)
+	g.eachInCells(topLeft, bottomRight, func(item T) bool {
+		if Intersects(rect, item.Rect()) {
+			found, ok = item, true
+			return false
+		}
+		return true
+	})
+	return found, ok
+}
+
+// nearest は (x, y) からの距離が maxDist 未満のもののうち、最も近いものとその距離を返す
+// filter が nil でなければ、filter が true を返すものだけを対象にする
+// (x, y) のマスから外側に向かって 1 周ずつ調べ、それより外側に近いものがありえなくなったらやめる
+func (g *spatialGrid[T]) nearest(x, y int, maxDist float64, filter func(T, float64) bool) (T, float64, bool) {
+	var best T
+	bestDist := math.MaxFloat64
+	var found bool
+
+	if g.len() == 0 {
+		return best, bestDist, false
+	}
+
+	center := cellOf(x, y)
+	visited := 0
+	for ring := 0; ; ring++ {
+		// ring 周目のマスにいるものは、少なくとも (ring-1) マス分は離れている
+		minDist := float64((ring - 1) * spatialCellSize)
+		if minDist >= maxDist || (found && minDist >= bestDist) || visited >= g.len() {
+			break
+		}
+
+		g.eachInRing(center, ring, func(item T) {
+			visited++
+			ix, iy := item.Position()
+			d := distance(x, y, ix, iy)
+			if d >= maxDist || d >= bestDist {
+				return
+			}
+			if filter != nil && !filter(item, d) {
+				return
+			}
+			best, bestDist, found = item, d, true
+		})
+	}
+
+	return best, bestDist, found
+}
+
+// center から ring マス離れた正方形の外周のマスを調べる
+func (g *spatialGrid[T]) eachInRing(center cellKey, ring int, fn func(T)) {
+	visit := func(x, y int) {
+		for _, item := range g.cells[cellKey{x, y}] {
+			fn(item)
+		}
+	}
+
+	if ring == 0 {
+		visit(center.x, center.y)
+		return
+	}
+
+	for x := center.x - ring; x <= center.x+ring; x++ {
+		visit(x, center.y-ring)
+		visit(x, center.y+ring)
+	}
+	for y := center.y - ring + 1; y <= center.y+ring-1; y++ {
+		visit(center.x-ring, y)
+		visit(center.x+ring, y)
+	}
+}
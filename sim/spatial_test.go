@@ -0,0 +1,121 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// ランダムに配置した虫について、空間インデックスの検索結果と全件を調べた結果が一致することを確かめる
+func randomBugs(r *rand.Rand, n int) []*Bug {
+	var bugs []*Bug
+	for i := 0; i < n; i++ {
+		// 画面外から出現するものもいるので、画面より広い範囲に置く
+		bugs = append(bugs, NewBug(BugKind(r.Intn(3)), r.Intn(ScreenWidth+400)-200, r.Intn(ScreenHeight+400)-200))
+	}
+	return bugs
+}
+
+func TestSpatialGridQueryRange(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	bugs := randomBugs(r, 300)
+	g := newSpatialGrid[*Bug]()
+	for _, b := range bugs {
+		g.insert(b)
+	}
+
+	for i := 0; i < 100; i++ {
+		x, y := r.Intn(ScreenWidth), r.Intn(ScreenHeight)
+		radius := float64(r.Intn(300))
+
+		want := 0
+		for _, b := range bugs {
+			if distance(x, y, b.X, b.Y) < radius {
+				want++
+			}
+		}
+		if got := len(g.queryRange(x, y, radius)); got != want {
+			t.Errorf("queryRange(%d, %d, %f): expected %d bugs, got %d", x, y, radius, want, got)
+		}
+	}
+}
+
+func TestSpatialGridQueryRect(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	bugs := randomBugs(r, 300)
+	g := newSpatialGrid[*Bug]()
+	for _, b := range bugs {
+		g.insert(b)
+	}
+
+	for i := 0; i < 100; i++ {
+		rect := Rect{r.Intn(ScreenWidth), r.Intn(ScreenHeight), r.Intn(200), r.Intn(200)}
+
+		want := 0
+		for _, b := range bugs {
+			if Intersects(rect, b.Rect()) {
+				want++
+			}
+		}
+		if got := len(g.queryRect(rect)); got != want {
+			t.Errorf("queryRect(%v): expected %d bugs, got %d", rect, want, got)
+		}
+		if _, ok := g.findRect(rect); ok != (want > 0) {
+			t.Errorf("findRect(%v): expected found to be %v", rect, want > 0)
+		}
+	}
+}
+
+func TestSpatialGridNearest(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	bugs := randomBugs(r, 50)
+	g := newSpatialGrid[*Bug]()
+	for _, b := range bugs {
+		g.insert(b)
+	}
+
+	for i := 0; i < 100; i++ {
+		x, y := r.Intn(ScreenWidth), r.Intn(ScreenHeight)
+		maxDist := math.MaxFloat64
+		if i%2 == 0 {
+			maxDist = float64(r.Intn(400))
+		}
+
+		wantDist := math.MaxFloat64
+		for _, b := range bugs {
+			if d := distance(x, y, b.X, b.Y); d < maxDist && d < wantDist {
+				wantDist = d
+			}
+		}
+
+		_, gotDist, ok := g.nearest(x, y, maxDist, nil)
+		if ok != (wantDist < math.MaxFloat64) {
+			t.Fatalf("nearest(%d, %d, %f): expected found to be %v", x, y, maxDist, wantDist < math.MaxFloat64)
+		}
+		if ok && gotDist != wantDist {
+			t.Errorf("nearest(%d, %d, %f): expected distance %f, got %f", x, y, maxDist, wantDist, gotDist)
+		}
+	}
+}
+
+func TestSpatialGridUpdate(t *testing.T) {
+	g := newSpatialGrid[*Bug]()
+	b := NewBug(BugRed, 10, 10)
+	g.insert(b)
+
+	// 遠くに動かしてもマスを付け替えれば見つかる
+	b.X, b.Y = -500, 700
+	g.update(b)
+
+	if len(g.queryRange(10, 10, 50)) != 0 {
+		t.Errorf("expected bug not to be found at the old position")
+	}
+	if len(g.queryRange(-500, 700, 1)) != 1 {
+		t.Errorf("expected bug to be found at the new position")
+	}
+
+	g.remove(b)
+	if g.len() != 0 || len(g.queryRange(-500, 700, 1)) != 0 {
+		t.Errorf("expected bug to be removed")
+	}
+}
@@ -0,0 +1,240 @@
+package sim
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// BugStats は虫の種類ごとの能力値
+type BugStats struct {
+	Name        string  `json:"name"`
+	Health      int     `json:"health"`
+	Speed       float64 `json:"speed"`
+	AttackPower int     `json:"attackPower"`
+	AttackRange float64 `json:"attackRange"`
+	// 攻撃してから次に攻撃できるまでのフレーム数
+	AttackInterval int `json:"attackInterval"`
+}
+
+// BuildingStats は建物の種類ごとの能力値
+// 攻撃しない建物は攻撃に関するものを 0 のままにしておく
+type BuildingStats struct {
+	Name   string `json:"name"`
+	Health int    `json:"health"`
+	// 建築に必要なクレジット。家は建築するものではないので 0
+	Cost int `json:"cost"`
+
+	AttackPower int `json:"attackPower"`
+	// tower の射程
+	AttackRange float64 `json:"attackRange"`
+	// radioTower の射程と、爆発の範囲
+	ShortAttackRange float64 `json:"shortAttackRange"`
+	LongAttackRange  float64 `json:"longAttackRange"`
+	AttackZoneRadius float64 `json:"attackZoneRadius"`
+	// 攻撃してから次に攻撃できるまでのフレーム数
+	AttackInterval int `json:"attackInterval"`
+	// 攻撃が当たった虫にかける状態異常。省略するとかけない
+	OnHit *StatusEffect `json:"onHit,omitempty"`
+}
+
+// 能力値の一覧は stats.json に書く
+// 起動時に LoadStats で別のファイルを読むと、そこに書かれた値で上書きできる
+//
+//go:embed stats.json
+var statsJSON []byte
+
+var (
+	bugStats      map[BugKind]*BugStats
+	buildingStats map[BuildingKind]*BuildingStats
+
+	statsOnce sync.Once
+)
+
+// stats.json の中での建物の名前
+// 虫の名前は registerBugKind で登録する
+var buildingKindNames = map[string]BuildingKind{
+	"house":      BuildingHouse,
+	"barricade":  BuildingBarricade,
+	"tower":      BuildingTower,
+	"radioTower": BuildingRadioTower,
+}
+
+// loadStats は最初に呼ばれたときに stats.json を読む
+// 虫の種類はそれぞれのファイルの init で登録されるので、すべての init が終わってから読む
+func loadStats() {
+	statsOnce.Do(func() {
+		bugs, buildings, err := parseStats(statsJSON, nil, nil)
+		if err != nil {
+			// 埋め込んだものが壊れていたらゲームにならないので panic する
+			panic(fmt.Sprintf("invalid stats.json: %v", err))
+		}
+		bugStats, buildingStats = bugs, buildings
+	})
+}
+
+// BugStatsOf は kind の虫の能力値を返す
+func BugStatsOf(kind BugKind) BugStats {
+	loadStats()
+	return *bugStats[kind]
+}
+
+// BuildingStatsOf は kind の建物の能力値を返す
+func BuildingStatsOf(kind BuildingKind) BuildingStats {
+	loadStats()
+	return *buildingStats[kind]
+}
+
+// LoadStats は data に書かれた値でいまの能力値を上書きする
+// 書かれていない虫や建物、値はそのまま残る
+// おかしな値があったときはエラーを返し、能力値は変わらない
+// 虫や建物を作る前 (起動時) に呼ぶこと
+func LoadStats(data []byte) error {
+	loadStats()
+	bugs, buildings, err := parseStats(data, bugStats, buildingStats)
+	if err != nil {
+		return err
+	}
+	bugStats, buildingStats = bugs, buildings
+	return nil
+}
+
+// parseStats は base に data を重ねた能力値を作って確かめる
+// base は変更しない
+func parseStats(data []byte, baseBugs map[BugKind]*BugStats, baseBuildings map[BuildingKind]*BuildingStats) (map[BugKind]*BugStats, map[BuildingKind]*BuildingStats, error) {
+	// 値をコピーしておき、そこに上書きする
+	bugsByName := map[string]*BugStats{}
+	for kind, spec := range bugKinds {
+		s := &BugStats{}
+		if base, ok := baseBugs[kind]; ok {
+			*s = *base
+		}
+		bugsByName[spec.name] = s
+	}
+	buildingsByName := map[string]*BuildingStats{}
+	for name, kind := range buildingKindNames {
+		s := &BuildingStats{}
+		if base, ok := baseBuildings[kind]; ok {
+			*s = *base
+			// 上書きするときに base の状態異常を書き換えないようにコピーしておく
+			if base.OnHit != nil {
+				onHit := *base.OnHit
+				s.OnHit = &onHit
+			}
+		}
+		buildingsByName[name] = s
+	}
+
+	var f struct {
+		Bugs      map[string]json.RawMessage `json:"bugs"`
+		Buildings map[string]json.RawMessage `json:"buildings"`
+	}
+	if err := strictUnmarshal(data, &f); err != nil {
+		return nil, nil, err
+	}
+	for name, raw := range f.Bugs {
+		s, ok := bugsByName[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown bug kind: %s", name)
+		}
+		if err := strictUnmarshal(raw, s); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	for name, raw := range f.Buildings {
+		s, ok := buildingsByName[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown building kind: %s", name)
+		}
+		if err := strictUnmarshal(raw, s); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	bugs := map[BugKind]*BugStats{}
+	for kind, spec := range bugKinds {
+		s := bugsByName[spec.name]
+		if err := s.validate(); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", spec.name, err)
+		}
+		bugs[kind] = s
+	}
+	buildings := map[BuildingKind]*BuildingStats{}
+	for name, s := range buildingsByName {
+		kind := buildingKindNames[name]
+		if err := s.validate(kind); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+		buildings[kind] = s
+	}
+
+	return bugs, buildings, nil
+}
+
+// 知らない項目があったら書き間違いなのでエラーにする
+func strictUnmarshal(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// 虫の速さの下限
+// 移動は縦横それぞれ 1 ドット未満を切り捨てるので、これより遅いと (やさしいで遅くなっても) 斜めに進めずに止まってしまう
+const minBugSpeed = 2
+
+func (s *BugStats) validate() error {
+	switch {
+	case s.Name == "":
+		return fmt.Errorf("name is missing")
+	case s.Health <= 0:
+		return fmt.Errorf("invalid health: %d", s.Health)
+	case s.Speed < minBugSpeed:
+		return fmt.Errorf("invalid speed: %v", s.Speed)
+	case s.AttackPower < 0:
+		return fmt.Errorf("invalid attack power: %d", s.AttackPower)
+	case s.AttackRange < 0:
+		return fmt.Errorf("invalid attack range: %v", s.AttackRange)
+	case s.AttackInterval <= 0:
+		return fmt.Errorf("invalid attack interval: %d", s.AttackInterval)
+	}
+	return nil
+}
+
+func (s *BuildingStats) validate(kind BuildingKind) error {
+	switch {
+	case s.Name == "":
+		return fmt.Errorf("name is missing")
+	case s.Health <= 0:
+		return fmt.Errorf("invalid health: %d", s.Health)
+	case s.Cost < 0:
+		return fmt.Errorf("invalid cost: %d", s.Cost)
+	case s.AttackPower < 0:
+		return fmt.Errorf("invalid attack power: %d", s.AttackPower)
+	}
+
+	switch kind {
+	case BuildingTower:
+		if s.AttackRange <= 0 {
+			return fmt.Errorf("invalid attack range: %v", s.AttackRange)
+		}
+	case BuildingRadioTower:
+		if s.ShortAttackRange < 0 || s.LongAttackRange <= s.ShortAttackRange {
+			return fmt.Errorf("invalid attack range: %v to %v", s.ShortAttackRange, s.LongAttackRange)
+		}
+		if s.AttackZoneRadius <= 0 {
+			return fmt.Errorf("invalid attack zone radius: %v", s.AttackZoneRadius)
+		}
+	}
+	if (kind == BuildingTower || kind == BuildingRadioTower) && s.AttackInterval <= 0 {
+		return fmt.Errorf("invalid attack interval: %d", s.AttackInterval)
+	}
+	if s.OnHit != nil {
+		if err := s.OnHit.validate(); err != nil {
+			return fmt.Errorf("onHit: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package sim
+
+import "testing"
+
+// LoadStats で上書きした能力値をテストが終わったら元に戻す
+func restoreStats(t *testing.T) {
+	loadStats()
+	bugs, buildings := bugStats, buildingStats
+	t.Cleanup(func() {
+		bugStats, buildingStats = bugs, buildings
+	})
+}
+
+func TestLoadStatsOverride(t *testing.T) {
+	restoreStats(t)
+
+	err := LoadStats([]byte(`{
+		"bugs": {"red": {"health": 10}},
+		"buildings": {"tower": {"cost": 999, "attackRange": 123}}
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	red := NewBug(BugRed, 0, 0)
+	if red.Health != 10 {
+		t.Errorf("expected overridden health 10, got %d", red.Health)
+	}
+	// 書かれていない値はそのまま
+	if red.Speed != 5 || red.Name != "Red bug" {
+		t.Errorf("expected the other values to stay, got speed %v name %q", red.Speed, red.Name)
+	}
+
+	tower := NewBuilding(BuildingTower, 0, 0)
+	if tower.Cost() != 999 || tower.AttackRange != 123 {
+		t.Errorf("expected overridden cost and range, got %d, %v", tower.Cost(), tower.AttackRange)
+	}
+	if tower.Health != 70 {
+		t.Errorf("expected health to stay 70, got %d", tower.Health)
+	}
+}
+
+func TestLoadStatsInvalid(t *testing.T) {
+	restoreStats(t)
+
+	for name, data := range map[string]string{
+		"unknown bug":      `{"bugs": {"yellow": {"health": 1}}}`,
+		"unknown building": `{"buildings": {"castle": {"health": 1}}}`,
+		"unknown field":    `{"bugs": {"red": {"hp": 1}}}`,
+		"health":           `{"bugs": {"red": {"health": 0}}}`,
+		"speed":            `{"bugs": {"blue": {"speed": -1}}}`,
+		"too slow":         `{"bugs": {"queen": {"speed": 1}}}`,
+		"cost":             `{"buildings": {"barricade": {"cost": -1}}}`,
+		"range":            `{"buildings": {"radioTower": {"shortAttackRange": 500}}}`,
+		"interval":         `{"buildings": {"tower": {"attackInterval": 0}}}`,
+		"syntax":           `{"bugs": `,
+	} {
+		if err := LoadStats([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// 失敗したときは何も変わらない
+	if NewBug(BugRed, 0, 0).Health != 3 {
+		t.Errorf("expected stats not to change after errors")
+	}
+}
@@ -0,0 +1,172 @@
+package sim
+
+import "fmt"
+
+// StatusKind は状態異常の種類
+type StatusKind int
+
+const (
+	// 鈍足: 移動が遅くなる
+	StatusSlow StatusKind = iota
+	// 気絶: 動くことも攻撃することもできない
+	StatusStun
+	// やけど: 一定時間ごとにダメージを受ける。重ならない
+	StatusBurn
+	// 毒: 一定時間ごとにダメージを受ける。重ねがけするほどダメージが増える
+	StatusPoison
+)
+
+// stats.json の中での名前
+var statusKindNames = map[string]StatusKind{
+	"slow":   StatusSlow,
+	"stun":   StatusStun,
+	"burn":   StatusBurn,
+	"poison": StatusPoison,
+}
+
+func (k StatusKind) MarshalText() ([]byte, error) {
+	for name, kind := range statusKindNames {
+		if kind == k {
+			return []byte(name), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown status kind: %d", k)
+}
+
+func (k *StatusKind) UnmarshalText(text []byte) error {
+	kind, ok := statusKindNames[string(text)]
+	if !ok {
+		return fmt.Errorf("unknown status kind: %s", text)
+	}
+	*k = kind
+	return nil
+}
+
+// やけどと毒がダメージを与える間隔 (フレーム数)
+const statusTickInterval = 30
+
+// statusRule は状態異常の種類ごとの重ねがけのきまり
+type statusRule struct {
+	// 重ねがけできる最大数
+	// 1 のときは重ならず、かけなおすと残りの長いほう、効き目の強いほうが残る
+	maxStacks int
+}
+
+var statusRules = map[StatusKind]statusRule{
+	StatusSlow:   {maxStacks: 1},
+	StatusStun:   {maxStacks: 1},
+	StatusBurn:   {maxStacks: 1},
+	StatusPoison: {maxStacks: 5},
+}
+
+// StatusEffect は虫にかける状態異常
+// 建物や手などは、虫の種類を気にせずにこれを ApplyStatus で虫にかければよい
+type StatusEffect struct {
+	Kind StatusKind `json:"kind"`
+	// 続くフレーム数
+	Duration int `json:"duration"`
+	// 鈍足のときの移動の速さの倍率 (0 より大きく 1 より小さい)
+	SpeedRate float64 `json:"speedRate,omitempty"`
+	// やけどと毒のときに statusTickInterval ごとに与えるダメージ
+	Damage int `json:"damage,omitempty"`
+}
+
+func (e StatusEffect) validate() error {
+	if e.Duration <= 0 {
+		return fmt.Errorf("invalid status duration: %d", e.Duration)
+	}
+	switch e.Kind {
+	case StatusSlow:
+		if e.SpeedRate <= 0 || e.SpeedRate >= 1 {
+			return fmt.Errorf("invalid slow speed rate: %v", e.SpeedRate)
+		}
+	case StatusBurn, StatusPoison:
+		if e.Damage <= 0 {
+			return fmt.Errorf("invalid status damage: %d", e.Damage)
+		}
+	case StatusStun:
+	default:
+		return fmt.Errorf("unknown status kind: %d", e.Kind)
+	}
+	return nil
+}
+
+// Status は虫にかかっている状態異常
+type Status struct {
+	StatusEffect
+
+	// 残りのフレーム数
+	Remaining int
+	// 重ねがけされている数
+	Stacks int
+
+	// 次にダメージを与えるまでのフレーム数
+	tick int
+}
+
+// ApplyStatus は b に状態異常 e をかける
+// すでに同じ種類がかかっているときは statusRules に従って重ねる
+func (b *Bug) ApplyStatus(e StatusEffect) {
+	if b.IsDead() {
+		return
+	}
+
+	s := b.status(e.Kind)
+	if s == nil {
+		b.Statuses = append(b.Statuses, &Status{
+			StatusEffect: e,
+			Remaining:    e.Duration,
+			Stacks:       1,
+			tick:         statusTickInterval,
+		})
+		return
+	}
+
+	s.Remaining = max(s.Remaining, e.Duration)
+	s.Damage = max(s.Damage, e.Damage)
+	s.SpeedRate = min(s.SpeedRate, e.SpeedRate)
+	s.Stacks = min(s.Stacks+1, statusRules[e.Kind].maxStacks)
+}
+
+// HasStatus は kind の状態異常がかかっているかどうかを返す
+func (b *Bug) HasStatus(kind StatusKind) bool {
+	return b.status(kind) != nil
+}
+
+func (b *Bug) status(kind StatusKind) *Status {
+	for _, s := range b.Statuses {
+		if s.Kind == kind {
+			return s
+		}
+	}
+	return nil
+}
+
+// speed は状態異常を反映した移動の速さを返す
+func (b *Bug) speed() float64 {
+	if s := b.status(StatusSlow); s != nil {
+		return b.Speed * s.SpeedRate
+	}
+	return b.Speed
+}
+
+// updateStatuses は状態異常の残り時間を減らし、やけどや毒のダメージを与える
+// 切れたものは取り除く
+func (b *Bug) updateStatuses(w *World) {
+	var rest []*Status
+	for _, s := range b.Statuses {
+		if s.Damage > 0 {
+			s.tick--
+			if s.tick <= 0 {
+				b.Damage(w, s.Damage*s.Stacks, DamageStatus)
+				s.tick = statusTickInterval
+			}
+		}
+
+		s.Remaining--
+		if s.Remaining > 0 {
+			rest = append(rest, s)
+		}
+	}
+	b.Statuses = rest
+}
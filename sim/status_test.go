@@ -0,0 +1,190 @@
+package sim
+
+import "testing"
+
+func TestStatusMovement(t *testing.T) {
+	for name, tc := range map[string]struct {
+		effects []StatusEffect
+		// 最初のフレームに進む距離の、ふだんの速さに対する倍率
+		speedRate float64
+		// すべての状態異常が切れるまでのフレーム数
+		duration int
+	}{
+		"no status": {speedRate: 1},
+		// 弱い鈍足をかけなおしても、強いほうが残る
+		"slow": {
+			effects:   []StatusEffect{{Kind: StatusSlow, Duration: 10, SpeedRate: 0.5}, {Kind: StatusSlow, Duration: 5, SpeedRate: 0.8}},
+			speedRate: 0.5,
+			duration:  10,
+		},
+		"stun": {
+			effects:   []StatusEffect{{Kind: StatusStun, Duration: 3}},
+			speedRate: 0,
+			duration:  3,
+		},
+	} {
+		w, b := spawnNearHouse(t, BugRed)
+		for _, e := range tc.effects {
+			b.ApplyStatus(e)
+		}
+
+		x := b.X
+		b.update(w)
+		if moved, want := x-b.X, int(b.Speed*tc.speedRate); moved != want {
+			t.Errorf("%s: expected the bug to move %d, got %d", name, want, moved)
+		}
+
+		for i := 1; i < tc.duration; i++ {
+			b.update(w)
+		}
+		if len(b.Statuses) != 0 {
+			t.Errorf("%s: expected the status to wear off after %d frames", name, tc.duration)
+		}
+	}
+}
+
+func TestStatusStunStopsBehaviors(t *testing.T) {
+	// 気絶している間は動かず、手下も呼ばない。甲羅が元に戻るまでの時間だけは進む
+	for name, tc := range map[string]struct {
+		kind   BugKind
+		frames int
+		// 気絶させる前に虫にすること
+		setup func(w *World, b *Bug)
+		// frames だけ進めたあとに、ふるまいの時間が進んでいたかどうか
+		ticked func(w *World, b *Bug) bool
+		want   bool
+	}{
+		"beetle recovers": {
+			kind:   BugBeetle,
+			frames: beetleFlipDuration,
+			setup:  func(w *World, b *Bug) { b.Damage(w, 1, DamageHand) },
+			ticked: func(w *World, b *Bug) bool { return !b.Flipped },
+			want:   true,
+		},
+		"queen does not spawn": {
+			kind:   BugQueen,
+			frames: queenSpawnInterval,
+			ticked: func(w *World, b *Bug) bool { return len(w.Bugs) != 1 },
+			want:   false,
+		},
+		"boss does not summon": {
+			kind:   BugBoss,
+			frames: 1,
+			setup:  func(w *World, b *Bug) { b.Damage(w, b.MaxHealth/2, DamageHand) },
+			ticked: func(w *World, b *Bug) bool { return len(w.Bugs) != 1 },
+			want:   false,
+		},
+	} {
+		w, b := spawnNearHouse(t, tc.kind)
+		if tc.setup != nil {
+			tc.setup(w, b)
+		}
+		b.ApplyStatus(StatusEffect{Kind: StatusStun, Duration: tc.frames * 2})
+
+		x := b.X
+		for i := 0; i < tc.frames; i++ {
+			b.update(w)
+		}
+		if got := tc.ticked(w, b); got != tc.want {
+			t.Errorf("%s: expected ticked %v while stunned, got %v", name, tc.want, got)
+		}
+		if b.X != x {
+			t.Errorf("%s: expected the stunned bug not to move", name)
+		}
+	}
+}
+
+func TestStatusTickDamage(t *testing.T) {
+	burn := StatusEffect{Kind: StatusBurn, Duration: statusTickInterval * 2, Damage: 1}
+	poison := StatusEffect{Kind: StatusPoison, Duration: statusTickInterval, Damage: 1}
+
+	for name, tc := range map[string]struct {
+		effect StatusEffect
+		// 何回かけるか
+		times  int
+		damage int
+	}{
+		// やけどは重ならない
+		"burn":         {effect: burn, times: 2, damage: 2},
+		"poison":       {effect: poison, times: 1, damage: 1},
+		"poison twice": {effect: poison, times: 2, damage: 2},
+		// 毒は重ねがけするほどダメージが増えるが、上限がある
+		"poison over the limit": {effect: poison, times: 10, damage: statusRules[StatusPoison].maxStacks},
+	} {
+		w, b := spawnNearHouse(t, BugBoss)
+		health := b.Health
+
+		for i := 0; i < tc.times; i++ {
+			b.ApplyStatus(tc.effect)
+		}
+		for i := 0; i < tc.effect.Duration; i++ {
+			b.update(w)
+		}
+		if got := health - b.Health; got != tc.damage {
+			t.Errorf("%s: expected %d damage, got %d", name, tc.damage, got)
+		}
+		if b.HasStatus(tc.effect.Kind) {
+			t.Errorf("%s: expected the status to wear off", name)
+		}
+	}
+}
+
+func TestRadioTowerBurns(t *testing.T) {
+	restoreStats(t)
+	err := LoadStats([]byte(`{"buildings": {"radioTower": {"onHit": {"kind": "burn", "duration": 90, "damage": 1}}}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 爆発が届く距離に置く
+	w, b := spawnNearHouse(t, BugBoss)
+	radioTower := NewBuilding(BuildingRadioTower, b.X-300, b.Y)
+	w.AddBuilding(radioTower)
+
+	radioTower.update(w)
+	if !b.HasStatus(StatusBurn) {
+		t.Errorf("expected the radio tower blast to burn the bug")
+	}
+}
+
+func TestLoadStatsOnHit(t *testing.T) {
+	restoreStats(t)
+
+	// stats.json ではどの建物も状態異常をかけない
+	for name, kind := range buildingKindNames {
+		if onHit := BuildingStatsOf(kind).OnHit; onHit != nil {
+			t.Errorf("expected %s to have no onHit by default, got %+v", name, onHit)
+		}
+	}
+
+	// 失敗したときは能力値が変わらないので、続けて試せる
+	for name, data := range map[string]string{
+		"unknown kind":  `{"buildings": {"tower": {"onHit": {"kind": "freeze", "duration": 30}}}}`,
+		"no duration":   `{"buildings": {"tower": {"onHit": {"kind": "stun"}}}}`,
+		"no speed rate": `{"buildings": {"tower": {"onHit": {"kind": "slow", "duration": 30}}}}`,
+		"no damage":     `{"buildings": {"tower": {"onHit": {"kind": "poison", "duration": 30}}}}`,
+	} {
+		if err := LoadStats([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	err := LoadStats([]byte(`{"buildings": {"tower": {"onHit": {"kind": "slow", "duration": 30, "speedRate": 0.5}}}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	onHit := NewBuilding(BuildingTower, 0, 0).OnHit
+	if onHit == nil || onHit.Kind != StatusSlow || onHit.SpeedRate != 0.5 {
+		t.Errorf("expected the tower to slow bugs, got %+v", onHit)
+	}
+
+	// 書かれていない値はそのまま。元の能力値は書き換わらない
+	base := buildingStats[BuildingTower].OnHit
+	if err := LoadStats([]byte(`{"buildings": {"tower": {"onHit": {"duration": 10}}}}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	onHit = NewBuilding(BuildingTower, 0, 0).OnHit
+	if onHit.Kind != StatusSlow || onHit.SpeedRate != 0.5 || onHit.Duration != 10 || base.Duration == 10 {
+		t.Errorf("expected only the duration to be overridden, got %+v (base %+v)", onHit, base)
+	}
+}
@@ -0,0 +1,182 @@
+package sim
+
+import (
+	_ "embed"
+	"math"
+	"math/rand"
+	"slices"
+	"sync"
+)
+
+type spawnInfo struct {
+	kind BugKind
+	x, y int
+}
+
+// 虫の種類ごとの出現の割合
+// トータル10になるようにする
+// waves.json を読むときに確かめている
+type bugSpawnRatio map[BugKind]int
+
+// pick は 0 から 9 までの n に対応する虫の種類を返す
+// 種類の順に割合を積み上げていき、n が入るところの種類にする
+func (r bugSpawnRatio) pick(n int) BugKind {
+	kinds := make([]BugKind, 0, len(r))
+	for kind := range r {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+
+	total := 0
+	for _, kind := range kinds {
+		total += r[kind]
+		if n < total {
+			return kind
+		}
+	}
+	return kinds[len(kinds)-1]
+}
+
+// 虫が出てくる画面の端
+type spawnSide int
+
+const (
+	sideTop spawnSide = iota
+	sideBottom
+	sideLeft
+	sideRight
+)
+
+// 出てくる端を指定しなかったときは四方八方から出てくる
+var allSides = []spawnSide{sideTop, sideBottom, sideLeft, sideRight}
+
+// 乱数はすべて r から取り出す。同じシードの r からは同じ結果が得られる
+func generateSpawnInfos(r *rand.Rand, num int, spawnRatio bugSpawnRatio, sides []spawnSide) []spawnInfo {
+	var infos []spawnInfo
+
+	for i := 0; i < num; i++ {
+		// sides の中からランダムに生成する
+		side := sides[r.Intn(len(sides))]
+		var x, y int
+		switch side {
+		case sideTop:
+			x = r.Intn(ScreenWidth)
+			y = -50
+		case sideBottom:
+			x = r.Intn(ScreenWidth)
+			y = ScreenHeight + 50
+		case sideLeft:
+			x = -50
+			y = r.Intn(ScreenHeight)
+		case sideRight:
+			x = ScreenWidth + 50
+			y = r.Intn(ScreenHeight)
+		}
+
+		kind := spawnRatio.pick(r.Intn(spawnRatioTotal))
+		infos = append(infos, spawnInfo{kind, x, y})
+	}
+
+	return infos
+}
+
+// ひとつの大きなウェーブは、決まったフレームに虫を出現させる小さなウェーブの集まり
+type smallWave struct {
+	spawnFrame    int
+	spawnInfoList []spawnInfo
+}
+
+// smallWaveSpec は小さなウェーブの作り方
+// どこから何が出てくるかはシードに従ってランダムに決める
+type smallWaveSpec struct {
+	spawnFrame int
+	num        int
+	ratio      bugSpawnRatio
+	sides      []spawnSide
+}
+
+// generateWaves は waveSpecs に従ってすべてのウェーブの中身を作る
+func generateWaves(r *rand.Rand) [][]smallWave {
+	var waves [][]smallWave
+	for _, specs := range waveSpecs() {
+		var wave []smallWave
+		for _, spec := range specs {
+			wave = append(wave, smallWave{spec.spawnFrame, generateSpawnInfos(r, spec.num, spec.ratio, spec.sides)})
+		}
+		waves = append(waves, wave)
+	}
+	return waves
+}
+
+// ウェーブの定義は waves.json に書く
+// Go のコードを触らずに、ウェーブや出現のタイミング、出てくる端を足したり変えたりできる
+// 後半のウェーブは戦闘力 (combatPower) が高くなるように設定している
+//
+//go:embed waves.json
+var wavesJSON []byte
+
+// 虫の種類はそれぞれのファイルの init で登録されるので、すべての init が終わってから最初に使うときに読む
+var (
+	waveSpecs   = sync.OnceValue(func() [][]smallWaveSpec { return mustParseWaveSpecs(wavesJSON) })
+	endlessWave = sync.OnceValue(func() endlessSpec { return mustParseEndlessSpec(wavesJSON) })
+)
+
+// ウェーブにおける敵の戦闘力は以下のように計算してみる
+// 1. 虫の種類ごとの戦闘力は registerBugKind で登録する (赤虫: 1, 青虫: 2, 緑虫: 3 など)
+// 2. それぞれの虫の数をかけて、それを足し合わせる
+// 3. それをウェーブの戦闘力とする
+// 例: 赤虫が 5, 青虫が 3, 緑虫が 2 の場合、戦闘力は 5*1 + 3*2 + 2*3 = 5 + 6 + 6 = 17 となる
+func combatPower(kind BugKind) int {
+	return bugKindSpecOf(kind).combatPower
+}
+
+// waveCombatPower はウェーブに出てくる虫すべての戦闘力の合計を返す
+func waveCombatPower(wave []smallWave) int {
+	power := 0
+	for _, sw := range wave {
+		for _, info := range sw.spawnInfoList {
+			power += combatPower(info.kind)
+		}
+	}
+	return power
+}
+
+// endlessSpec はエンドレスモードで waveSpecs を使い切ったあとのウェーブの作り方
+type endlessSpec struct {
+	basePower float64
+	growth    float64
+	exponent  float64
+
+	spawnInterval int
+	spawnSize     int
+	ratio         bugSpawnRatio
+}
+
+// power は waveSpecs を使い切ってから n 個目 (0 始まり) のウェーブの戦闘力を返す
+func (s endlessSpec) power(n int) int {
+	return int(s.basePower + s.growth*math.Pow(float64(n), s.exponent))
+}
+
+// generate は n 個目のウェーブを作る
+// 戦闘力が power(n) に届くまで虫を足していき、spawnSize ずつ spawnInterval おきに出す
+func (s endlessSpec) generate(r *rand.Rand, n int) []smallWave {
+	target := s.power(n)
+
+	var infos []spawnInfo
+	power := 0
+	for power < target {
+		info := generateSpawnInfos(r, 1, s.ratio, allSides)[0]
+		infos = append(infos, info)
+		power += combatPower(info.kind)
+	}
+
+	var wave []smallWave
+	for i := 0; i < len(infos); i += s.spawnSize {
+		end := min(i+s.spawnSize, len(infos))
+		wave = append(wave, smallWave{
+			spawnFrame:    s.spawnInterval * len(wave),
+			spawnInfoList: infos[i:end],
+		})
+	}
+	return wave
+}
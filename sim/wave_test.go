@@ -0,0 +1,180 @@
+package sim
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateWavesDeterministic(t *testing.T) {
+	w1 := generateWaves(rand.New(rand.NewSource(42)))
+	w2 := generateWaves(rand.New(rand.NewSource(42)))
+	w3 := generateWaves(rand.New(rand.NewSource(43)))
+
+	if !reflect.DeepEqual(w1, w2) {
+		t.Errorf("expected the same seed to generate the same waves")
+	}
+	if reflect.DeepEqual(w1, w3) {
+		t.Errorf("expected different seeds to generate different waves")
+	}
+	if len(w1) != len(waveSpecs()) {
+		t.Errorf("expected %d waves, got %d", len(waveSpecs()), len(w1))
+	}
+}
+
+// 同じシードで同じように進めたら、同じところに同じ虫が出てくる
+func TestWorldDeterministicSpawns(t *testing.T) {
+	spawns := func(seed int64) [][3]int {
+		w := NewWorld(seed)
+		var ret [][3]int
+		Subscribe(w.Events, func(e BugSpawned) {
+			ret = append(ret, [3]int{int(e.Bug.Kind), e.Bug.X, e.Bug.Y})
+		})
+		runWave(t, w, 60*60)
+		return ret
+	}
+
+	s1 := spawns(7)
+	s2 := spawns(7)
+	if len(s1) == 0 {
+		t.Fatalf("expected some bugs to spawn")
+	}
+	if !reflect.DeepEqual(s1, s2) {
+		t.Errorf("expected identical spawns for the same seed")
+	}
+}
+
+func TestParseWaveSpecs(t *testing.T) {
+	specs, err := parseWaveSpecs([]byte(`{"waves": [{"spawns": [
+		{"frame": 0, "count": 3, "ratio": {"red": 4, "green": 6}},
+		{"frame": 30, "count": 2, "ratio": {"blue": 10}, "sides": ["left", "right"]}
+	]}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := [][]smallWaveSpec{{
+		{spawnFrame: 0, num: 3, ratio: bugSpawnRatio{BugRed: 4, BugGreen: 6}, sides: allSides},
+		{spawnFrame: 30, num: 2, ratio: bugSpawnRatio{BugBlue: 10}, sides: []spawnSide{sideLeft, sideRight}},
+	}}
+	if !reflect.DeepEqual(specs, want) {
+		t.Errorf("expected %+v, got %+v", want, specs)
+	}
+}
+
+func TestParseWaveSpecsInvalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"no waves":       `{"waves": []}`,
+		"no spawns":      `{"waves": [{"spawns": []}]}`,
+		"first frame":    `{"waves": [{"spawns": [{"frame": 10, "count": 1, "ratio": {"red": 10}}]}]}`,
+		"frame order":    `{"waves": [{"spawns": [{"frame": 0, "count": 1, "ratio": {"red": 10}}, {"frame": 0, "count": 1, "ratio": {"red": 10}}]}]}`,
+		"count":          `{"waves": [{"spawns": [{"frame": 0, "count": 0, "ratio": {"red": 10}}]}]}`,
+		"ratio total":    `{"waves": [{"spawns": [{"frame": 0, "count": 1, "ratio": {"red": 5, "blue": 4}}]}]}`,
+		"negative ratio": `{"waves": [{"spawns": [{"frame": 0, "count": 1, "ratio": {"red": 11, "blue": -1}}]}]}`,
+		"bug kind":       `{"waves": [{"spawns": [{"frame": 0, "count": 1, "ratio": {"yellow": 10}}]}]}`,
+		"side":           `{"waves": [{"spawns": [{"frame": 0, "count": 1, "ratio": {"red": 10}, "sides": ["middle"]}]}]}`,
+		"duplicated":     `{"waves": [{"spawns": [{"frame": 0, "count": 1, "ratio": {"red": 10}, "sides": ["top", "top"]}]}]}`,
+		"syntax":         `{"waves": [`,
+		// 書き間違えたキーは無視せずにエラーにする
+		"unknown key": `{"waves": [{"spawns": [{"frame": 0, "count": 1, "ratio": {"red": 10}, "side": ["top"]}]}]}`,
+	} {
+		if _, err := parseWaveSpecs([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestGenerateSpawnInfosSides(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, info := range generateSpawnInfos(r, 20, bugSpawnRatio{BugRed: 10}, []spawnSide{sideLeft}) {
+		if info.x != -50 {
+			t.Errorf("expected every bug to spawn on the left, got (%d, %d)", info.x, info.y)
+		}
+	}
+}
+
+func TestEndlessSpecGenerate(t *testing.T) {
+	spec := endlessSpec{basePower: 50, growth: 10, exponent: 1.5, spawnInterval: 30, spawnSize: 8, ratio: bugSpawnRatio{BugRed: 3, BugBlue: 5, BugGreen: 2}}
+
+	prev := 0
+	for n := 0; n < 5; n++ {
+		r := rand.New(rand.NewSource(int64(n)))
+		wave := spec.generate(r, n)
+
+		// 戦闘力は曲線に届いていて、最後に足した虫のぶんしか超えない
+		power := waveCombatPower(wave)
+		if power < spec.power(n) || power >= spec.power(n)+3 {
+			t.Errorf("wave %d: expected combat power around %d, got %d", n, spec.power(n), power)
+		}
+		if power <= prev {
+			t.Errorf("wave %d: expected combat power to grow, got %d after %d", n, power, prev)
+		}
+		prev = power
+
+		for i, sw := range wave {
+			if sw.spawnFrame != i*spec.spawnInterval {
+				t.Errorf("wave %d: expected small wave %d at frame %d, got %d", n, i, i*spec.spawnInterval, sw.spawnFrame)
+			}
+			if len(sw.spawnInfoList) == 0 || len(sw.spawnInfoList) > spec.spawnSize {
+				t.Errorf("wave %d: invalid small wave size %d", n, len(sw.spawnInfoList))
+			}
+		}
+	}
+}
+
+func TestParseEndlessSpecInvalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"missing":     `{"waves": []}`,
+		"power":       `{"endless": {"basePower": 0, "growth": 1, "exponent": 1, "spawnInterval": 60, "spawnSize": 10, "ratio": {"red": 10}}}`,
+		"exponent":    `{"endless": {"basePower": 10, "growth": 1, "exponent": 0, "spawnInterval": 60, "spawnSize": 10, "ratio": {"red": 10}}}`,
+		"interval":    `{"endless": {"basePower": 10, "growth": 1, "exponent": 1, "spawnInterval": 0, "spawnSize": 10, "ratio": {"red": 10}}}`,
+		"size":        `{"endless": {"basePower": 10, "growth": 1, "exponent": 1, "spawnInterval": 60, "spawnSize": 0, "ratio": {"red": 10}}}`,
+		"ratio":       `{"endless": {"basePower": 10, "growth": 1, "exponent": 1, "spawnInterval": 60, "spawnSize": 10, "ratio": {"red": 9}}}`,
+		"unknown key": `{"endless": {"basePower": 10, "growth": 1, "exponent": 1, "interval": 60, "spawnInterval": 60, "spawnSize": 10, "ratio": {"red": 10}}}`,
+	} {
+		if _, err := parseEndlessSpec([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestWorldEndless(t *testing.T) {
+	var allCleared bool
+	w := NewWorld(3)
+	w.Endless = true
+	Subscribe(w.Events, func(AllCleared) { allCleared = true })
+
+	// 最後のウェーブを終えたことにする
+	w.Wave = w.WaveCount() - 1
+	w.StartWave()
+	w.smallWave = len(w.waves[w.Wave])
+	w.Bugs = nil
+	w.checkWaveEnd()
+	w.Events.Dispatch()
+
+	if allCleared {
+		t.Errorf("expected no clear in endless mode")
+	}
+	if w.Wave != w.WaveCount() {
+		t.Fatalf("expected wave %d, got %d", w.WaveCount(), w.Wave)
+	}
+
+	// waves.json を超えたウェーブも作られる
+	w.StartWave()
+	if len(w.waves) != w.WaveCount()+1 {
+		t.Fatalf("expected an endless wave to be generated, got %d waves", len(w.waves))
+	}
+	if waveCombatPower(w.waves[w.Wave]) < endlessWave().power(0) {
+		t.Errorf("expected the endless wave to follow the curve")
+	}
+
+	// セーブデータから再開しても同じウェーブになる
+	restored, err := RestoreWorld(Snapshot{Version: SnapshotVersion, Seed: 3, Endless: true, Wave: w.Wave, HouseHealth: 100, Difficulty: DifficultyNormal})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	restored.StartWave()
+	if !reflect.DeepEqual(restored.waves, w.waves) {
+		t.Errorf("expected the same endless waves after restore")
+	}
+}
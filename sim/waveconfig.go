@@ -0,0 +1,214 @@
+package sim
+
+import "fmt"
+
+// bugSpawnRatio の合計
+const spawnRatioTotal = 10
+
+// waves.json の形
+type waveFile struct {
+	Waves []waveEntry `json:"waves"`
+
+	// エンドレスモードで waves を使い切ったあとのウェーブの作り方
+	Endless *endlessEntry `json:"endless"`
+}
+
+// endlessEntry はエンドレスモードのウェーブの作り方
+// waves を使い切ってから n 個目 (0 始まり) のウェーブの戦闘力は basePower + growth * n^exponent になる
+type endlessEntry struct {
+	BasePower float64 `json:"basePower"`
+	Growth    float64 `json:"growth"`
+	Exponent  float64 `json:"exponent"`
+	// 小さなウェーブの間隔 (フレーム数) と、ひとつの小さなウェーブで出てくる虫の最大数
+	SpawnInterval int `json:"spawnInterval"`
+	SpawnSize     int `json:"spawnSize"`
+	// 虫の種類ごとの割合。合計は 10
+	Ratio map[string]int `json:"ratio"`
+}
+
+type waveEntry struct {
+	// 戦闘力などのメモ。読むときには使わない
+	Comment string       `json:"comment"`
+	Spawns  []spawnEntry `json:"spawns"`
+}
+
+// spawnEntry は小さなウェーブひとつ
+type spawnEntry struct {
+	// ウェーブが始まってから何フレーム目に出てくるか
+	Frame int `json:"frame"`
+	// 出てくる虫の数
+	Count int `json:"count"`
+	// 虫の種類 (red や queen など、stats.json の bugs と同じ名前) ごとの割合。合計は 10
+	Ratio map[string]int `json:"ratio"`
+	// 出てくる端 (top, bottom, left, right)。省略すると四方八方から出てくる
+	Sides []string `json:"sides"`
+}
+
+var spawnSideNames = map[string]spawnSide{
+	"top":    sideTop,
+	"bottom": sideBottom,
+	"left":   sideLeft,
+	"right":  sideRight,
+}
+
+// parseWaveSpecs は waves.json の中身を読んで、おかしなところがあればエラーを返す
+func parseWaveSpecs(data []byte) ([][]smallWaveSpec, error) {
+	var f waveFile
+	if err := strictUnmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	if len(f.Waves) == 0 {
+		return nil, fmt.Errorf("no waves")
+	}
+
+	var waves [][]smallWaveSpec
+	for i, wave := range f.Waves {
+		if len(wave.Spawns) == 0 {
+			return nil, fmt.Errorf("wave %d: no spawns", i)
+		}
+
+		var specs []smallWaveSpec
+		for j, entry := range wave.Spawns {
+			spec, err := parseSpawnEntry(entry)
+			if err != nil {
+				return nil, fmt.Errorf("wave %d, spawn %d: %w", i, j, err)
+			}
+
+			// ウェーブが始まってから虫のいない時間ができないように、最初のフレームでかならず虫を出す
+			if j == 0 && spec.spawnFrame != 0 {
+				return nil, fmt.Errorf("wave %d, spawn %d: first spawn must be at frame 0, got %d", i, j, spec.spawnFrame)
+			}
+			// 小さなウェーブは順番に出していくので、フレームは増えていかないといけない
+			if j > 0 && spec.spawnFrame <= specs[j-1].spawnFrame {
+				return nil, fmt.Errorf("wave %d, spawn %d: frame %d must be after %d", i, j, spec.spawnFrame, specs[j-1].spawnFrame)
+			}
+
+			specs = append(specs, spec)
+		}
+		waves = append(waves, specs)
+	}
+
+	return waves, nil
+}
+
+func parseSpawnEntry(e spawnEntry) (smallWaveSpec, error) {
+	spec := smallWaveSpec{
+		spawnFrame: e.Frame,
+		num:        e.Count,
+	}
+
+	if e.Frame < 0 {
+		return spec, fmt.Errorf("invalid frame: %d", e.Frame)
+	}
+	if e.Count <= 0 {
+		return spec, fmt.Errorf("invalid count: %d", e.Count)
+	}
+
+	ratio, err := parseSpawnRatio(e.Ratio)
+	if err != nil {
+		return spec, err
+	}
+	spec.ratio = ratio
+
+	if len(e.Sides) == 0 {
+		spec.sides = allSides
+		return spec, nil
+	}
+	seen := map[spawnSide]bool{}
+	for _, name := range e.Sides {
+		side, ok := spawnSideNames[name]
+		if !ok {
+			return spec, fmt.Errorf("unknown side: %s", name)
+		}
+		if seen[side] {
+			return spec, fmt.Errorf("duplicated side: %s", name)
+		}
+		seen[side] = true
+		spec.sides = append(spec.sides, side)
+	}
+
+	return spec, nil
+}
+
+func parseSpawnRatio(m map[string]int) (bugSpawnRatio, error) {
+	ratio := bugSpawnRatio{}
+
+	total := 0
+	for name, n := range m {
+		if n < 0 {
+			return ratio, fmt.Errorf("invalid ratio for %s: %d", name, n)
+		}
+		// 名前は stats.json と同じものを使う
+		kind, ok := bugKindByName(name)
+		if !ok {
+			return ratio, fmt.Errorf("unknown bug kind: %s", name)
+		}
+		if n > 0 {
+			ratio[kind] = n
+		}
+		total += n
+	}
+	if total != spawnRatioTotal {
+		return ratio, fmt.Errorf("ratio must add up to %d, got %d", spawnRatioTotal, total)
+	}
+
+	return ratio, nil
+}
+
+// parseEndlessSpec は waves.json の中のエンドレスモードの設定を読む
+func parseEndlessSpec(data []byte) (endlessSpec, error) {
+	var spec endlessSpec
+
+	var f waveFile
+	if err := strictUnmarshal(data, &f); err != nil {
+		return spec, err
+	}
+	e := f.Endless
+	if e == nil {
+		return spec, fmt.Errorf("endless is missing")
+	}
+
+	switch {
+	case e.BasePower <= 0:
+		return spec, fmt.Errorf("endless: invalid base power: %v", e.BasePower)
+	case e.Growth < 0:
+		return spec, fmt.Errorf("endless: invalid growth: %v", e.Growth)
+	case e.Exponent <= 0:
+		return spec, fmt.Errorf("endless: invalid exponent: %v", e.Exponent)
+	case e.SpawnInterval <= 0:
+		return spec, fmt.Errorf("endless: invalid spawn interval: %d", e.SpawnInterval)
+	case e.SpawnSize <= 0:
+		return spec, fmt.Errorf("endless: invalid spawn size: %d", e.SpawnSize)
+	}
+	ratio, err := parseSpawnRatio(e.Ratio)
+	if err != nil {
+		return spec, fmt.Errorf("endless: %w", err)
+	}
+
+	return endlessSpec{
+		basePower:     e.BasePower,
+		growth:        e.Growth,
+		exponent:      e.Exponent,
+		spawnInterval: e.SpawnInterval,
+		spawnSize:     e.SpawnSize,
+		ratio:         ratio,
+	}, nil
+}
+
+func mustParseEndlessSpec(data []byte) endlessSpec {
+	spec, err := parseEndlessSpec(data)
+	if err != nil {
+		panic(fmt.Sprintf("invalid waves.json: %v", err))
+	}
+	return spec
+}
+
+// mustParseWaveSpecs は埋め込んだ waves.json を読む
+// 埋め込んだものが壊れていたらゲームにならないので panic する
+func mustParseWaveSpecs(data []byte) [][]smallWaveSpec {
+	specs, err := parseWaveSpecs(data)
+	if err != nil {
+		panic(fmt.Sprintf("invalid waves.json: %v", err))
+	}
+	return specs
+}
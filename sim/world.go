@@ -0,0 +1,266 @@
+// Package sim はウェーブと戦闘のシミュレーションを行う
+// 描画や音には依存しないので、ウィンドウやオーディオのない環境 (go test など) でもウェーブを最後まで進められる
+package sim
+
+import "math/rand"
+
+const (
+	ScreenWidth  = 1280
+	ScreenHeight = 960
+
+	// 画面の下には情報パネルが敷かれる
+	// 虫や建物が動き回るのは情報パネルを除いた領域
+	FieldHeight = ScreenHeight - ScreenHeight/7 - 10
+)
+
+type World struct {
+	House *Building
+
+	// 家を含む建物のリスト
+	Buildings []*Building
+
+	// 敵のリスト
+	Bugs []*Bug
+
+	Hand *Hand
+
+	// 虫と建物を座標で探すための空間インデックス
+	bugIndex      *spatialGrid[*Bug]
+	buildingIndex *spatialGrid[*Building]
+
+	Credit int
+
+	// 難易度
+	// 虫の強さ、お金、家の体力が変わる
+	Difficulty Difficulty
+
+	// このゲームのシード
+	// ゲームの中の乱数はすべて Rand から取り出すので、同じシードなら同じウェーブになる
+	Seed int64
+	Rand *rand.Rand
+	// シードから作ったウェーブの中身
+	// エンドレスモードでは、waveSpecs を使い切ったあとのウェーブを始めるときに足していく
+	waves [][]smallWave
+
+	// エンドレスモードかどうか
+	// true のときはすべてのウェーブを終えてもクリアにならず、ゲームオーバーになるまでウェーブが続く
+	Endless bool
+
+	// 現在の (あるいは次に始まる) ウェーブの番号
+	Wave int
+	// ウェーブの中でいくつ目の小さなウェーブまで出現させたか
+	smallWave int
+	// ウェーブが始まってから経過したフレーム数
+	erapsedFrame int
+
+	waveRunning bool
+	gameOver    bool
+
+	// 最後に割り当てた ID
+	lastEntityID EntityID
+
+	// シミュレーションの中で起きたことはここに発行される
+	// 購読する側は適当なタイミングで Dispatch を呼ぶ
+	Events *EventBus
+}
+
+func NewWorld(seed int64) *World {
+	r := rand.New(rand.NewSource(seed))
+
+	w := &World{
+		Hand:          newHand(),
+		Seed:          seed,
+		Rand:          r,
+		waves:         generateWaves(r),
+		bugIndex:      newSpatialGrid[*Bug](),
+		buildingIndex: newSpatialGrid[*Building](),
+		Events:        NewEventBus(),
+	}
+
+	w.House = NewBuilding(BuildingHouse, ScreenWidth/2, FieldHeight/2)
+	w.AddBuilding(w.House)
+	w.SetDifficulty(DifficultyNormal)
+
+	return w
+}
+
+// WaveCount は waves.json に書かれたウェーブの総数を返す
+// エンドレスモードではこれを超えてもウェーブが続く
+func (w *World) WaveCount() int {
+	return len(waveSpecs())
+}
+
+func (w *World) WaveRunning() bool {
+	return w.waveRunning
+}
+
+func (w *World) IsGameOver() bool {
+	return w.gameOver
+}
+
+// StartWave は現在のウェーブを開始する
+func (w *World) StartWave() {
+	if w.Endless {
+		w.generateEndlessWaves()
+	}
+
+	w.waveRunning = true
+	w.smallWave = 0
+	w.erapsedFrame = 0
+	w.Events.Publish(WaveStarted{Wave: w.Wave})
+}
+
+// Step はシミュレーションを 1 フレーム進める
+func (w *World) Step() {
+	if w.waveRunning {
+		w.spawnBugs()
+		w.erapsedFrame++
+	}
+
+	// 更新中に取り除かれるものがあるのでコピーしてから回す
+	for _, b := range append([]*Building{}, w.Buildings...) {
+		b.update(w)
+	}
+	for _, b := range append([]*Bug{}, w.Bugs...) {
+		b.update(w)
+		// 動いた虫のマスを付け替える
+		w.bugIndex.update(b)
+	}
+	w.Hand.update(w)
+
+	if w.waveRunning {
+		w.checkWaveEnd()
+	}
+}
+
+func (w *World) spawnBugs() {
+	// erapsedFrame に従って敵を生成する
+	if w.Wave >= len(w.waves) {
+		return
+	}
+	if w.smallWave >= len(w.waves[w.Wave]) {
+		return
+	}
+
+	sw := w.waves[w.Wave][w.smallWave]
+	if w.erapsedFrame != sw.spawnFrame {
+		return
+	}
+
+	for _, info := range sw.spawnInfoList {
+		w.AddBug(NewBug(info.kind, info.x, info.y))
+	}
+	w.smallWave++
+}
+
+func (w *World) checkWaveEnd() {
+	if w.House.Health <= 0 {
+		// ゲームオーバー
+		w.waveRunning = false
+		w.gameOver = true
+		w.Events.Publish(GameOver{})
+		return
+	}
+
+	// まだ出てきていない small wave があるうちは、敵が 0 になってもウェーブは終わらない
+	// 最後の small wave (ボスなど) より前に敵を全部倒しても、最後まで出てくる
+	if w.smallWave < len(w.waves[w.Wave]) {
+		return
+	}
+	if len(w.Bugs) != 0 {
+		return
+	}
+
+	w.waveRunning = false
+	cleared := w.Wave
+	w.Wave++
+
+	// ウェーブ終了時に一定のクレジットを得る
+	w.Credit += w.Difficulty.WaveClearReward
+
+	w.Events.Publish(WaveCleared{Wave: cleared})
+
+	if !w.Endless && w.Wave == len(w.waves) {
+		w.Events.Publish(AllCleared{})
+	}
+}
+
+// generateEndlessWaves は現在のウェーブまでの中身を作る
+// 乱数を使う順番を変えないように、ウェーブの順に作る
+func (w *World) generateEndlessWaves() {
+	for len(w.waves) <= w.Wave {
+		w.waves = append(w.waves, endlessWave().generate(w.Rand, len(w.waves)-len(waveSpecs())))
+	}
+}
+
+// World に置かれる虫はすべて難易度にあわせた強さになる
+func (w *World) AddBug(b *Bug) {
+	w.Difficulty.applyTo(b)
+	b.ID = w.newEntityID()
+	w.Bugs = append(w.Bugs, b)
+	w.bugIndex.insert(b)
+	w.Events.Publish(BugSpawned{Bug: b})
+}
+
+func (w *World) removeBug(b *Bug) {
+	for i, bug := range w.Bugs {
+		if bug == b {
+			w.Bugs = append(w.Bugs[:i], w.Bugs[i+1:]...)
+			break
+		}
+	}
+	w.bugIndex.remove(b)
+	w.Events.Publish(BugRemoved{Bug: b})
+}
+
+// CanBuild は b を建築できるかどうかを返す
+// お金が足りていて、他の建物と重なっていなければ建築できる
+func (w *World) CanBuild(b *Building) bool {
+	return w.Credit >= b.Cost() && !w.Overlaps(b)
+}
+
+// Build は b を建築してクレジットを減らす
+func (w *World) Build(b *Building) {
+	w.Credit -= b.Cost()
+	w.AddBuilding(b)
+	w.Events.Publish(BuildingPlaced{Building: b})
+}
+
+// AddBuilding はクレジットを消費せずに建物を置く
+func (w *World) AddBuilding(b *Building) {
+	b.ID = w.newEntityID()
+	w.Buildings = append(w.Buildings, b)
+	w.buildingIndex.insert(b)
+}
+
+func (w *World) removeBuilding(b *Building) {
+	for i, building := range w.Buildings {
+		if building == b {
+			w.Buildings = append(w.Buildings[:i], w.Buildings[i+1:]...)
+			break
+		}
+	}
+	w.buildingIndex.remove(b)
+	w.Events.Publish(BuildingRemoved{Building: b})
+}
+
+// Overlaps は b が他の建物と重なっているかどうかを返す
+func (w *World) Overlaps(b *Building) bool {
+	for _, building := range w.buildingIndex.queryRect(b.Rect()) {
+		if building != b {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 家がまだ取り除かれていないかどうか
+func (w *World) houseExists() bool {
+	for _, b := range w.Buildings {
+		if b == w.House {
+			return true
+		}
+	}
+	return false
+}
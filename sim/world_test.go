@@ -0,0 +1,172 @@
+package sim
+
+import (
+	"testing"
+)
+
+// ウェーブが終わるかゲームオーバーになるまでシミュレーションを進める
+// 進めたフレーム数を返す
+func runWave(t *testing.T, w *World, maxFrame int) int {
+	t.Helper()
+
+	w.StartWave()
+	for frame := 0; frame < maxFrame; frame++ {
+		w.Step()
+		w.Events.Dispatch()
+		if !w.WaveRunning() {
+			return frame
+		}
+	}
+
+	t.Fatalf("wave %d did not finish in %d frames", w.Wave, maxFrame)
+	return maxFrame
+}
+
+func TestWorldGameOverWithoutDefense(t *testing.T) {
+	var gameOver bool
+	w := NewWorld(1)
+	Subscribe(w.Events, func(GameOver) { gameOver = true })
+
+	runWave(t, w, 60*60)
+
+	if !gameOver || !w.IsGameOver() {
+		t.Errorf("expected game over without any defense")
+	}
+	if w.House.Health != 0 {
+		t.Errorf("expected house health to be 0, got %d", w.House.Health)
+	}
+	if w.Wave != 0 {
+		t.Errorf("expected wave not to advance, got %d", w.Wave)
+	}
+}
+
+func TestWorldWaveClearWithTowers(t *testing.T) {
+	var cleared []int
+	var killed, spawned int
+	w := NewWorld(1)
+	Subscribe(w.Events, func(BugSpawned) { spawned++ })
+	Subscribe(w.Events, func(BugKilled) { killed++ })
+	Subscribe(w.Events, func(e WaveCleared) { cleared = append(cleared, e.Wave) })
+
+	// 家の四方にタワーを置く
+	hx, hy := w.House.Position()
+	w.AddBuilding(NewBuilding(BuildingTower, hx-100, hy))
+	w.AddBuilding(NewBuilding(BuildingTower, hx+100, hy))
+	w.AddBuilding(NewBuilding(BuildingTower, hx, hy-120))
+	w.AddBuilding(NewBuilding(BuildingTower, hx, hy+120))
+
+	runWave(t, w, 60*60)
+
+	if w.IsGameOver() {
+		t.Fatalf("expected the first wave to be cleared by towers")
+	}
+	if len(cleared) != 1 || cleared[0] != 0 {
+		t.Errorf("expected wave 0 to be cleared, got %v", cleared)
+	}
+	if w.Wave != 1 {
+		t.Errorf("expected next wave to be 1, got %d", w.Wave)
+	}
+	if spawned == 0 || killed != spawned {
+		t.Errorf("expected all spawned bugs to be killed, spawned %d killed %d", spawned, killed)
+	}
+	want := DifficultyNormal.StartingCredit + DifficultyNormal.WaveClearReward
+	if w.Credit != want {
+		t.Errorf("expected credit %d, got %d", want, w.Credit)
+	}
+	if len(w.Bugs) != 0 {
+		t.Errorf("expected no bugs remaining, got %d", len(w.Bugs))
+	}
+}
+
+func TestWorldSlap(t *testing.T) {
+	var slapped int
+	w := NewWorld(1)
+	Subscribe(w.Events, func(HandSlapped) { slapped++ })
+
+	b := NewBug(BugRed, 100, 100)
+	w.AddBug(b)
+
+	if !w.Slap(100, 100) {
+		t.Fatalf("expected the first slap to be accepted")
+	}
+	w.Step()
+	if w.Slap(100, 100) {
+		t.Errorf("expected the slap during cooldown to be rejected")
+	}
+
+	for i := 0; i < 4; i++ {
+		w.Step()
+	}
+	w.Events.Dispatch()
+
+	if slapped != 1 {
+		t.Errorf("expected one slap, got %d", slapped)
+	}
+	if !b.IsDead() {
+		t.Errorf("expected red bug to be killed by a slap, health %d", b.Health)
+	}
+
+	// 死亡時のアニメーションが終わったら取り除かれる
+	for i := 0; i < DeadAnimationTotalFrame; i++ {
+		w.Step()
+	}
+	if len(w.Bugs) != 0 {
+		t.Errorf("expected dead bug to be removed")
+	}
+	if w.Hand.Visible() {
+		t.Errorf("expected hand to disappear")
+	}
+}
+
+func TestWorldOverlaps(t *testing.T) {
+	w := NewWorld(1)
+
+	hx, hy := w.House.Position()
+	if !w.Overlaps(NewBuilding(BuildingBarricade, hx, hy)) {
+		t.Errorf("expected barricade on the house to overlap")
+	}
+
+	b := NewBuilding(BuildingBarricade, hx+300, hy)
+	if w.Overlaps(b) {
+		t.Errorf("expected barricade far from the house not to overlap")
+	}
+	if !w.CanBuild(b) {
+		t.Errorf("expected barricade to be buildable")
+	}
+
+	w.Build(b)
+	cost := BuildingStatsOf(BuildingBarricade).Cost
+	if w.Credit != DifficultyNormal.StartingCredit-cost {
+		t.Errorf("expected credit %d, got %d", DifficultyNormal.StartingCredit-cost, w.Credit)
+	}
+	if w.CanBuild(NewBuilding(BuildingRadioTower, hx-300, hy)) {
+		t.Errorf("expected radio tower not to be buildable without enough credit")
+	}
+}
+
+func TestWorldEntityID(t *testing.T) {
+	w := NewWorld(1)
+
+	if w.House.ID == 0 {
+		t.Errorf("expected house to have an ID")
+	}
+
+	b := NewBuilding(BuildingBarricade, 100, 100)
+	if b.ID != 0 {
+		t.Errorf("expected building not added to the world to have no ID, got %d", b.ID)
+	}
+	w.AddBuilding(b)
+
+	bug1 := NewBug(BugRed, 0, 0)
+	bug2 := NewBug(BugRed, 0, 0)
+	w.AddBug(bug1)
+	w.AddBug(bug2)
+
+	ids := map[EntityID]bool{}
+	for _, id := range []EntityID{w.House.ID, b.ID, bug1.ID, bug2.ID} {
+		if ids[id] {
+			t.Errorf("expected unique IDs, got duplicated %d", id)
+		}
+		ids[id] = true
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 早送りの最大倍率
+const maxGameSpeed = 3
+
+// speedControl はウェーブフェーズの一時停止と早送りを受け持つ
+// 画面右上のボタンか、キーボード (P かスペースで一時停止、1 から 3 で倍率) で操作する
+type speedControl struct {
+	game *Game
+
+	paused bool
+	// 1 フレームの間にシミュレーションを進める回数
+	speed int
+}
+
+func newSpeedControl(g *Game) *speedControl {
+	return &speedControl{
+		game:  g,
+		speed: 1,
+	}
+}
+
+func (c *speedControl) TogglePause() {
+	c.paused = !c.paused
+	getAudioPlayer().play(soundChoice)
+}
+
+func (c *speedControl) SetSpeed(speed int) {
+	if speed < 1 || maxGameSpeed < speed {
+		return
+	}
+	c.speed = speed
+	c.paused = false
+	getAudioPlayer().play(soundChoice)
+}
+
+// steps はこのフレームの間にゲーム本編を進める回数を返す
+// 一時停止中は 0 になる。早送りできるのはウェーブフェーズの間だけ
+func (c *speedControl) steps() int {
+	if c.game.phase != PhaseWave {
+		return 1
+	}
+	if c.paused {
+		return 0
+	}
+	return c.speed
+}
+
+// handleKeys はキーボードでの操作を受け付ける
+func (c *speedControl) handleKeys() {
+	if c.game.phase != PhaseWave {
+		return
+	}
+
+	// 記録や再生ができるように、キーボードは直接読まずに Game の入力から見る
+	in := c.game.input
+	if in.keyJustPressed(ebiten.KeyP) || in.keyJustPressed(ebiten.KeySpace) {
+		c.TogglePause()
+	}
+	for speed, key := range []ebiten.Key{ebiten.Key1, ebiten.Key2, ebiten.Key3} {
+		if in.keyJustPressed(key) {
+			c.SetSpeed(speed + 1)
+		}
+	}
+}
+
+// addButtons は一時停止と倍率のボタンを h に登録する
+// ウェーブフェーズの間だけ表示するので、フェーズのハンドラに登録する
+func (c *speedControl) addButtons(h *handlerSet) {
+	const size = 50
+	const margin = 10
+	x := screenWidth - (size+margin)*(maxGameSpeed+1)
+
+	pause := newButton(c.game, x, margin, size, size, 150,
+		func(x, y int) bool {
+			c.TogglePause()
+			return false
+		},
+		func(screen *ebiten.Image, x, y, width, height int) {
+			drawRect(screen, x, y, width, height)
+			if c.paused {
+				ebitenutil.DebugPrintAt(screen, ">", x+width/2-3, y+height/2-8)
+				drawYellowRect(screen, x, y, width, height)
+			} else {
+				ebitenutil.DebugPrintAt(screen, "||", x+width/2-6, y+height/2-8)
+			}
+		})
+	h.clickHandler.Add(pause)
+	h.drawHandler.Add(pause)
+
+	for i := 1; i <= maxGameSpeed; i++ {
+		speed := i
+		b := newButton(c.game, x+(size+margin)*speed, margin, size, size, 150,
+			func(x, y int) bool {
+				c.SetSpeed(speed)
+				return false
+			},
+			func(screen *ebiten.Image, x, y, width, height int) {
+				drawRect(screen, x, y, width, height)
+				ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%dx", speed), x+width/2-6, y+height/2-8)
+				if !c.paused && c.speed == speed {
+					drawYellowRect(screen, x, y, width, height)
+				}
+			})
+		h.clickHandler.Add(b)
+		h.drawHandler.Add(b)
+	}
+
+	h.drawHandler.Add(&pausedText{control: c})
+}
+
+// 一時停止中に画面を暗くして PAUSED と出す
+type pausedText struct {
+	control *speedControl
+}
+
+func (p *pausedText) Draw(screen *ebiten.Image) {
+	if !p.control.paused {
+		return
+	}
+	vector.DrawFilledRect(screen, 0, 0, screenWidth, eScreenHeight, color.RGBA{0, 0, 0, 0x60}, true)
+	drawText(screen, "PAUSED", screenWidth/2-90, eScreenHeight/2-30, 5, 5, color.RGBA{0xff, 0xff, 0xff, 0xff})
+}
+
+func (p *pausedText) ZIndex() int {
+	return 140
+}
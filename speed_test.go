@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/pankona/gj/sim"
+)
+
+func TestSpeedControlSteps(t *testing.T) {
+	g := &Game{phase: PhaseBuilding}
+	c := newSpeedControl(g)
+	g.speedCtrl = c
+
+	c.speed = 3
+	if c.steps() != 1 {
+		t.Errorf("expected no fast-forward during the building phase, got %d", c.steps())
+	}
+
+	g.phase = PhaseWave
+	if c.steps() != 3 {
+		t.Errorf("expected 3 steps at 3x, got %d", c.steps())
+	}
+
+	c.paused = true
+	if c.steps() != 0 {
+		t.Errorf("expected no steps while paused, got %d", c.steps())
+	}
+}
+
+func TestPlaySceneFastForward(t *testing.T) {
+	g := &Game{
+		scenes:        &sceneManager{},
+		phase:         PhaseWave,
+		phaseHandlers: newHandlerSet(),
+		world:         sim.NewWorld(1),
+	}
+	g.speedCtrl = newSpeedControl(g)
+	s := newPlayScene(g)
+
+	u := &MockUpdater{}
+	g.phaseHandlers.updateHandler.Add(u)
+
+	g.speedCtrl.speed = 2
+	s.Update()
+	if u.updated != 2 {
+		t.Errorf("expected 2 updates at 2x, got %d", u.updated)
+	}
+
+	g.speedCtrl.paused = true
+	s.Update()
+	if u.updated != 2 {
+		t.Errorf("expected no updates while paused, got %d", u.updated)
+	}
+}
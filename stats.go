@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pankona/gj/sim"
+)
+
+// gameStats はゲーム 1 回分の記録
+// シミュレーションのイベントを購読して数える
+type gameStats struct {
+	bugsKilled      int
+	buildingsPlaced int
+	buildingsLost   int
+	slaps           int
+	// 家が受けたダメージの合計
+	houseDamage  int
+	wavesCleared int
+}
+
+func (s *gameStats) subscribe(bus *sim.EventBus) {
+	sim.Subscribe(bus, func(e sim.BugKilled) {
+		s.bugsKilled++
+	})
+	sim.Subscribe(bus, func(e sim.BuildingPlaced) {
+		s.buildingsPlaced++
+	})
+	sim.Subscribe(bus, func(e sim.BuildingDestroyed) {
+		if e.Building.Kind != sim.BuildingHouse {
+			s.buildingsLost++
+		}
+	})
+	sim.Subscribe(bus, func(e sim.BuildingDamaged) {
+		if e.Building.Kind == sim.BuildingHouse {
+			s.houseDamage += e.Damage
+		}
+	})
+	sim.Subscribe(bus, func(e sim.HandSlapped) {
+		s.slaps++
+	})
+	sim.Subscribe(bus, func(e sim.WaveCleared) {
+		s.wavesCleared++
+	})
+}
+
+// summary はゲームオーバーやゲームクリアの画面に出す一行
+func (s *gameStats) summary() string {
+	return fmt.Sprintf("Bugs killed: %d  Buildings placed: %d  Slaps: %d", s.bugsKilled, s.buildingsPlaced, s.slaps)
+}
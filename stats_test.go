@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/pankona/gj/sim"
+)
+
+func TestGameStats(t *testing.T) {
+	bus := sim.NewEventBus()
+	s := &gameStats{}
+	s.subscribe(bus)
+
+	house := sim.NewBuilding(sim.BuildingHouse, 0, 0)
+	tower := sim.NewBuilding(sim.BuildingTower, 100, 0)
+
+	bus.Publish(sim.BuildingPlaced{Building: tower})
+	bus.Publish(sim.BugKilled{Bug: sim.NewBug(sim.BugRed, 0, 0)})
+	bus.Publish(sim.BuildingDamaged{Building: house, Damage: 3})
+	bus.Publish(sim.BuildingDamaged{Building: tower, Damage: 5})
+	bus.Publish(sim.BuildingDestroyed{Building: tower})
+	bus.Publish(sim.WaveCleared{Wave: 0})
+	bus.Dispatch()
+
+	if s.bugsKilled != 1 || s.buildingsPlaced != 1 || s.buildingsLost != 1 || s.wavesCleared != 1 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+	if s.houseDamage != 3 {
+		t.Errorf("expected house damage 3, got %d", s.houseDamage)
+	}
+}
+
+func TestAchievementTracker(t *testing.T) {
+	g := &Game{
+		drawHandler:   &DrawHandler{},
+		updateHandler: &UpdateHandler{},
+	}
+	bus := sim.NewEventBus()
+	s := &gameStats{}
+	s.subscribe(bus)
+	tracker := newAchievementTracker(g, s)
+	tracker.subscribe(bus)
+
+	bus.Publish(sim.BugKilled{Bug: sim.NewBug(sim.BugRed, 0, 0)})
+	bus.Dispatch()
+
+	if !tracker.unlocked["First Blood"] {
+		t.Errorf("expected First Blood to be unlocked")
+	}
+	if len(g.updateHandler.updaters) != 1 {
+		t.Errorf("expected unlock message to be shown once, got %d", len(g.updateHandler.updaters))
+	}
+
+	// 一度解除されたものはもう出ない
+	bus.Publish(sim.BugKilled{Bug: sim.NewBug(sim.BugRed, 0, 0)})
+	bus.Dispatch()
+	if len(g.updateHandler.updaters) != 1 {
+		t.Errorf("expected unlock message not to be shown again, got %d", len(g.updateHandler.updaters))
+	}
+}
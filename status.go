@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/pankona/gj/sim"
+)
+
+// statusLook は状態異常ごとの見た目
+type statusLook struct {
+	// 虫の上に出すアイコンの文字と色
+	label string
+	icon  color.RGBA
+	// かかっている虫の色味
+	tint color.RGBA
+}
+
+var statusLooks = map[sim.StatusKind]statusLook{
+	sim.StatusSlow:   {label: "S", icon: color.RGBA{R: 0x40, G: 0x80, B: 0xff, A: 0xff}, tint: color.RGBA{R: 0x90, G: 0xb0, B: 0xff, A: 0xff}},
+	sim.StatusStun:   {label: "Z", icon: color.RGBA{R: 0xe0, G: 0xc0, B: 0x20, A: 0xff}, tint: color.RGBA{R: 0xff, G: 0xff, B: 0x90, A: 0xff}},
+	sim.StatusBurn:   {label: "B", icon: color.RGBA{R: 0xff, G: 0x60, B: 0x20, A: 0xff}, tint: color.RGBA{R: 0xff, G: 0x90, B: 0x60, A: 0xff}},
+	sim.StatusPoison: {label: "P", icon: color.RGBA{R: 0xa0, G: 0x40, B: 0xe0, A: 0xff}, tint: color.RGBA{R: 0xc0, G: 0x80, B: 0xff, A: 0xff}},
+}
+
+// 色味をつける状態異常の優先順位
+// いくつもかかっているときは、先にあるものの色味にする
+var statusTintOrder = []sim.StatusKind{
+	sim.StatusStun,
+	sim.StatusBurn,
+	sim.StatusPoison,
+	sim.StatusSlow,
+}
+
+// tintStatus はかかっている状態異常のうち、優先順位が一番高いものの色味を opts につける
+func tintStatus(opts *ebiten.DrawImageOptions, m *sim.Bug) {
+	for _, kind := range statusTintOrder {
+		if m.HasStatus(kind) {
+			opts.ColorScale.ScaleWithColor(statusLooks[kind].tint)
+			return
+		}
+	}
+}
+
+// drawStatusIcons は虫の頭の上に、かかっている状態異常のアイコンを横に並べる
+// 重ねがけされているものは数も出す
+func drawStatusIcons(screen *ebiten.Image, m *sim.Bug, y int) {
+	const (
+		radius = 7
+		gap    = 2
+	)
+
+	n := len(m.Statuses)
+	if n == 0 {
+		return
+	}
+
+	x := m.X - (n*(radius*2+gap)-gap)/2 + radius
+	iy := y - int(float64(m.Height)*m.Scale/2) - radius - 4
+	for _, s := range m.Statuses {
+		look := statusLooks[s.Kind]
+		vector.DrawFilledCircle(screen, float32(x), float32(iy), radius, look.icon, true)
+		// 文字は 6x12 ドットなので、真ん中に寄せる
+		drawText(screen, look.label, x-3, iy-6, 1, 1, color.RGBA{0xff, 0xff, 0xff, 0xff})
+		if s.Stacks > 1 {
+			drawText(screen, fmt.Sprint(s.Stacks), x+radius-2, iy-radius-4, 1, 1, color.RGBA{0xff, 0xff, 0xff, 0xff})
+		}
+		x += radius*2 + gap
+	}
+}
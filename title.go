@@ -2,15 +2,69 @@ package main
 
 import (
 	"bytes"
+	"fmt"
 	"image"
 	"image/color"
 	"log"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/pankona/gj/sim"
 )
 
-type title struct {
+// タイトル画面のシーン
+type titleScene struct {
+	*handlerSet
+
 	game *Game
+}
+
+func newTitleScene(g *Game) *titleScene {
+	return &titleScene{
+		handlerSet: newHandlerSet(),
+		game:       g,
+	}
+}
+
+func (s *titleScene) OnEnter() {
+	// いったん BGM 止める
+	getAudioPlayer().stopBGM()
+
+	t := newTitle(s.game, s)
+	s.drawHandler.Add(t)
+	s.clickHandler.Add(t)
+
+	in := newSeedInput(s.game)
+	s.drawHandler.Add(in)
+	s.updateHandler.Add(in)
+
+	m := newModeButton(s.game)
+	s.drawHandler.Add(m)
+	s.clickHandler.Add(m)
+
+	d := newDifficultyButton(s.game)
+	s.drawHandler.Add(d)
+	s.clickHandler.Add(d)
+
+	// セーブデータがあれば続きから始めるボタンを出す
+	if snapshot, err := loadSnapshot(); err == nil {
+		b := newContinueButton(s.game, snapshot)
+		s.drawHandler.Add(b)
+		s.clickHandler.Add(b)
+	}
+}
+
+func (s *titleScene) OnExit() {
+	getAudioPlayer().playBGM()
+
+	// タイトルで受け付けた入力は記録しない
+	// ゲーム本編が始まるところから記録しなおす
+	s.game.startRecording()
+}
+
+type title struct {
+	game  *Game
+	scene *titleScene
 
 	stopFrame int
 
@@ -21,10 +75,7 @@ type title struct {
 	greenBugImg  *ebiten.Image
 }
 
-func newTitle(g *Game) *title {
-	// いったん BGM 止める
-	getAudioPlayer().stopBGM()
-
+func newTitle(g *Game, scene *titleScene) *title {
 	houseImg, _, err := image.Decode(bytes.NewReader(houseImageData))
 	if err != nil {
 		log.Fatal(err)
@@ -43,6 +94,7 @@ func newTitle(g *Game) *title {
 
 	return &title{
 		game:         g,
+		scene:        scene,
 		stopFrame:    120,
 		houseImg:     ebiten.NewImageFromImage(houseImg),
 		barricadeImg: ebiten.NewImageFromImage(barricadeImg),
@@ -55,8 +107,8 @@ func newTitle(g *Game) *title {
 func (t *title) OnClick(x, y int) bool {
 	// タイタオルバックを非表示にする
 	getAudioPlayer().play(soundShot)
-	t.game.clickHandler.Remove(t)
-	t.game.updateHandler.Add(t)
+	t.scene.clickHandler.Remove(t)
+	t.scene.updateHandler.Add(t)
 	return false
 }
 
@@ -64,9 +116,15 @@ func (t *title) Update() {
 	// 数病経過したらゲーム画面に遷移する
 	t.stopFrame--
 	if t.stopFrame <= 0 {
-		t.game.drawHandler.Remove(t)
-		t.game.updateHandler.Remove(t)
-		getAudioPlayer().playBGM()
+		// タイトルのシーンを取り除くと下に積んであるゲーム本編が始まる
+		t.game.scenes.Pop()
+
+		// タイトルでシードやモード、難易度が変えられていたら、それにあわせてゲーム本編を作りなおす
+		// シードはタイトルに出ていたものをそのまま使う
+		w := t.game.world
+		if w.Seed != t.game.seed || w.Endless != t.game.endless || w.Difficulty != t.game.difficulty {
+			t.game.Restart()
+		}
 	}
 }
 
@@ -141,3 +199,37 @@ func (t *title) Draw(screen *ebiten.Image) {
 func (t *title) ZIndex() int {
 	return 300
 }
+
+// newModeButton は通常のモードとエンドレスモードを切り替えるボタンを作る
+func newModeButton(g *Game) *Button {
+	return newButton(g, screenWidth-750, 410, 500, 60, 320,
+		func(x, y int) bool {
+			getAudioPlayer().play(soundChoice)
+			g.endless = !g.endless
+			return false
+		},
+		func(screen *ebiten.Image, x, y, width, height int) {
+			clr := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+			vector.StrokeRect(screen, float32(x), float32(y), float32(width), float32(height), 2, clr, true)
+			mode := "MODE: NORMAL"
+			if g.endless {
+				mode = fmt.Sprintf("MODE: ENDLESS (BEST %d)", g.bestWaves)
+			}
+			drawText(screen, mode, x+20, y+15, 3, 3, clr)
+		})
+}
+
+// newContinueButton はセーブデータ s の続きから始めるボタンを作る
+// タイトルより手前にあるので、押してもタイトルのクリックにはならない
+func newContinueButton(g *Game, s *sim.Snapshot) *Button {
+	return newButton(g, screenWidth-750, 330, 500, 60, 320,
+		func(x, y int) bool {
+			getAudioPlayer().play(soundShot)
+			g.Continue(s)
+			return false
+		},
+		func(screen *ebiten.Image, x, y, width, height int) {
+			vector.StrokeRect(screen, float32(x), float32(y), float32(width), float32(height), 2, color.White, true)
+			drawText(screen, fmt.Sprintf("CONTINUE (WAVE %d)", s.Wave+1), x+20, y+15, 3, 3, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+		})
+}
@@ -5,10 +5,10 @@ import (
 	"fmt"
 	"image"
 	"log"
-	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/pankona/gj/sim"
 
 	_ "embed"
 	"image/color"
@@ -21,34 +21,27 @@ var towerImageData []byte
 type tower struct {
 	game *Game
 
-	x, y          int
+	// シミュレーション上の建物
+	model *sim.Building
+
 	width, height int
 	zindex        int
 	image         *ebiten.Image
 
-	health      int
-	attackRange float64
-	attackPower int
-	cooldown    int
-	erapsedTime int // 攻撃実行からの経過時間
-
 	// 画像の拡大率。
 	// 1以外を指定する場合は元画像のサイズをそもそも変更できないか検討すること
 	scale float64
 
-	// 死亡時のアニメーションを管理するための変数
-	deadAnimationDuration int
-
-	// health が 0 になったときに呼ばれる関数
-	onDestroy func(b *tower)
-
 	// この建物が他の建物と重なっているかどうか (建築確定前に用いるフラグ)
 	isOverlapping bool
+
+	// 壊れたときに崩れるアニメーション
+	collapse *Animation
 }
 
-const towerAttackCoolDown = 30
+func newTower(game *Game, x, y int) *tower {
+	loadAnimations()
 
-func newTower(game *Game, x, y int, onDestroy func(b *tower)) *tower {
 	img, _, err := image.Decode(bytes.NewReader(towerImageData))
 	if err != nil {
 		log.Fatal(err)
@@ -57,79 +50,18 @@ func newTower(game *Game, x, y int, onDestroy func(b *tower)) *tower {
 	h := &tower{
 		game: game,
 
-		x:      x,
-		y:      y,
+		model: sim.NewBuilding(sim.BuildingTower, x, y),
+
 		width:  img.Bounds().Dx(),
 		height: img.Bounds().Dy(),
 		scale:  1,
 
-		health:      70,
-		attackRange: 300,
-		attackPower: 1,
-
 		image: ebiten.NewImageFromImage(img),
-
-		onDestroy: onDestroy,
 	}
 
 	return h
 }
 
-func (t *tower) Update() {
-	if t.game.phase == PhaseBuilding {
-		// do nothing
-		return
-	}
-
-	// 死亡時のアニメーションを再生する
-	if t.health <= 0 {
-		t.deadAnimationDuration++
-		if t.deadAnimationDuration >= deadAnimationTotalFrame {
-			t.onDestroy(t)
-		}
-		return
-	}
-
-	// 家が壊れていたらもはや攻撃をやめる
-	if t.game.house.health <= 0 {
-		return
-	}
-
-	// 敵が攻撃範囲に入ってきたら攻撃する
-	// 複数の敵が攻撃範囲に入ってきた場合は、最も近い敵を攻撃する
-
-	// 最寄りの敵を探す
-	var nearestEnemy Enemy
-	nearestDistance := math.MaxFloat64
-	for _, e := range t.game.enemies {
-		ex, ey := e.Position()
-		distance := math.Sqrt(math.Pow(float64(t.x-ex), 2) + math.Pow(float64(t.y-ey), 2))
-		if distance < nearestDistance {
-			nearestEnemy = e
-			nearestDistance = distance
-		}
-	}
-
-	// クールダウンが明けていて、かつ攻撃範囲に入っていれば攻撃する
-	if t.cooldown == 0 && nearestEnemy != nil && nearestDistance < t.attackRange {
-		bx, by := nearestEnemy.Position()
-		b := nearestEnemy.(Damager)
-
-		getAudioPlayer().play(soundBeam)
-
-		b.Damage(t.attackPower)
-		t.cooldown = towerAttackCoolDown
-
-		// ビームを描画する
-		bm := newBeam(t.game, t.x, t.y, bx, by)
-		t.game.drawHandler.Add(bm)
-	}
-
-	if t.cooldown > 0 {
-		t.cooldown--
-	}
-}
-
 // タワーから発射されるビームを描画するための構造体
 type beam struct {
 	game *Game
@@ -171,25 +103,19 @@ func (b *beam) ZIndex() int {
 
 // 画面中央に配置
 func (b *tower) Draw(screen *ebiten.Image) {
+	m := b.model
+
 	// 画像を描画
 	opts := &ebiten.DrawImageOptions{}
 
-	if b.health <= 0 {
+	img := b.image
+	if b.collapse != nil {
 		// 死亡時のアニメーションを行う
-		// ぺちゃんこになるように縮小する
-		scale := 1.0 - float64(b.deadAnimationDuration)/deadAnimationTotalFrame
-		if scale < 0 {
-			scale = 0
-		}
-
-		opts.GeoM.Translate(0, float64(-b.height))
-		opts.GeoM.Scale(1, scale)
-		opts.GeoM.Translate(0, float64(b.height))
-	} else {
-		opts.GeoM.Scale(b.scale, b.scale)
+		img = b.collapse.Frame()
 	}
+	opts.GeoM.Scale(b.scale, b.scale)
 
-	opts.GeoM.Translate(float64(b.x)-float64(b.width)*b.scale/2, float64(b.y)-float64(b.height)*b.scale/2)
+	opts.GeoM.Translate(float64(m.X)-float64(b.width)*b.scale/2, float64(m.Y)-float64(b.height)*b.scale/2)
 
 	// 他の建物と重なっている場合は赤くする
 	if b.isOverlapping {
@@ -199,40 +125,42 @@ func (b *tower) Draw(screen *ebiten.Image) {
 		opts.ColorScale.Scale(0.5, 0.5, 0.5, 1)
 	}
 
-	screen.DrawImage(b.image, opts)
+	screen.DrawImage(img, opts)
+}
+
+// 壊れたら崩れるアニメーションを始める
+func (b *tower) Update() {
+	if b.collapse == nil {
+		if b.model.Health > 0 {
+			return
+		}
+		b.collapse = newAnimation(buildingCollapseClips[b.model.Kind], nil)
+	}
+	b.collapse.Update()
 }
 
 func (b *tower) ZIndex() int {
 	return b.zindex
 }
 
+func (b *tower) Model() *sim.Building {
+	return b.model
+}
+
 func (b *tower) Position() (int, int) {
-	return b.x, b.y
+	return b.model.Position()
 }
 
 func (b *tower) SetPosition(x, y int) {
-	b.x = x
-	b.y = y
+	b.model.SetPosition(x, y)
 }
 
 func (b *tower) Size() (int, int) {
-	return int(float64(b.width) * b.scale), int(float64(b.height) * b.scale)
+	return b.model.Size()
 }
 
 func (b *tower) Name() string {
-	return "Tower"
-}
-
-func (b *tower) Damage(d int) {
-	if b.health <= 0 {
-		return
-	}
-
-	b.health -= d
-	if b.health <= 0 {
-		getAudioPlayer().play(soundKuzureru)
-		b.health = 0
-	}
+	return b.model.Name()
 }
 
 // tower implements Clickable interface
@@ -273,12 +201,13 @@ func newTowerIcon(x, y int) *icon {
 }
 
 func (b *tower) Health() int {
-	return b.health
+	return b.model.Health
 }
 
 func (b *tower) IsClicked(x, y int) bool {
+	bx, by := b.Position()
 	w, h := b.Size()
-	return b.x-w/2 <= x && x <= b.x+w/2 && b.y-h/2 <= y && y <= b.y+h/2
+	return bx-w/2 <= x && x <= bx+w/2 && by-h/2 <= y && y <= by+h/2
 }
 
 func (b *tower) SetOverlap(overlap bool) {
@@ -287,25 +216,9 @@ func (b *tower) SetOverlap(overlap bool) {
 
 func (b *tower) IsOverlap() bool {
 	// 他の建物と重なっているかどうかを判定する
-	for _, building := range b.game.buildings {
-		if building == b {
-			continue
-		}
-
-		bx, by := building.Position()
-		bw, bh := building.Size()
-
-		if intersects(
-			rect{b.x - b.width/2, b.y - b.height/2, b.width, b.height},
-			rect{bx - bw/2, by - bh/2, bw, bh},
-		) {
-			return true
-		}
-	}
-
-	return false
+	return b.game.world.Overlaps(b.model)
 }
 
 func (b *tower) Cost() int {
-	return CostTowerBuild
+	return b.model.Cost()
 }
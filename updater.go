@@ -6,19 +6,41 @@ type Updater interface {
 
 type UpdateHandler struct {
 	updaters []Updater
+
+	// HandleUpdate の最中かどうか
+	// 最中に Add/Remove されたものは pending にためておき、HandleUpdate の最後に反映する
+	dispatching bool
+	pending     pendingQueue[Updater]
 }
 
 func (u *UpdateHandler) Add(updater Updater) {
+	if u.dispatching {
+		// 追加されたものはこのフレームでは呼ばれない
+		u.pending.add(updater)
+		return
+	}
 	u.updaters = append(u.updaters, updater)
 }
 
 func (u *UpdateHandler) HandleUpdate() {
+	u.dispatching = true
 	for _, updater := range u.updaters {
+		// このフレームの中ですでに取り除かれたものは呼ばない
+		if u.pending.isRemoved(updater) {
+			continue
+		}
 		updater.Update()
 	}
+	u.dispatching = false
+
+	u.pending.flush(u.Add, u.Remove)
 }
 
 func (u *UpdateHandler) Remove(updater Updater) {
+	if u.dispatching {
+		u.pending.remove(updater)
+		return
+	}
 	for i, v := range u.updaters {
 		if v == updater {
 			u.updaters = append(u.updaters[:i], u.updaters[i+1:]...)
@@ -28,5 +50,13 @@ func (u *UpdateHandler) Remove(updater Updater) {
 }
 
 func (u *UpdateHandler) Clear() {
+	u.pending.reset()
+	if u.dispatching {
+		// 呼び出し中のものも含めてすべて取り除く
+		for _, v := range u.updaters {
+			u.pending.remove(v)
+		}
+		return
+	}
 	u.updaters = nil
 }
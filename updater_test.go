@@ -0,0 +1,121 @@
+package main
+
+import (
+	"testing"
+)
+
+// MockUpdater is a mock implementation of the Updater interface for testing purposes.
+type MockUpdater struct {
+	updated  int
+	onUpdate func()
+}
+
+func (m *MockUpdater) Update() {
+	m.updated++
+	if m.onUpdate != nil {
+		m.onUpdate()
+	}
+}
+
+func TestUpdateHandlerAddRemove(t *testing.T) {
+	handler := &UpdateHandler{}
+	obj1 := &MockUpdater{}
+	obj2 := &MockUpdater{}
+
+	handler.Add(obj1)
+	handler.Add(obj2)
+	handler.Remove(obj1)
+
+	if len(handler.updaters) != 1 || handler.updaters[0] != obj2 {
+		t.Errorf("expected only obj2 to remain")
+	}
+}
+
+func TestUpdateHandlerSelfRemoval(t *testing.T) {
+	handler := &UpdateHandler{}
+	obj1 := &MockUpdater{}
+	obj2 := &MockUpdater{}
+	obj3 := &MockUpdater{}
+	obj1.onUpdate = func() { handler.Remove(obj1) }
+	obj2.onUpdate = func() { handler.Remove(obj2) }
+
+	handler.Add(obj1)
+	handler.Add(obj2)
+	handler.Add(obj3)
+
+	handler.HandleUpdate()
+
+	// 自分を取り除いても次の Updater が飛ばされない
+	if obj1.updated != 1 || obj2.updated != 1 || obj3.updated != 1 {
+		t.Errorf("expected every updater to be updated once, got %d, %d, %d", obj1.updated, obj2.updated, obj3.updated)
+	}
+	if len(handler.updaters) != 1 || handler.updaters[0] != obj3 {
+		t.Errorf("expected only obj3 to remain after the update")
+	}
+}
+
+func TestUpdateHandlerRemoveOtherDuringUpdate(t *testing.T) {
+	handler := &UpdateHandler{}
+	obj1 := &MockUpdater{}
+	obj2 := &MockUpdater{}
+	obj1.onUpdate = func() { handler.Remove(obj2) }
+
+	handler.Add(obj1)
+	handler.Add(obj2)
+
+	handler.HandleUpdate()
+
+	if obj2.updated != 0 {
+		t.Errorf("updater removed earlier in the same frame should not be updated")
+	}
+	if len(handler.updaters) != 1 {
+		t.Errorf("expected 1 updater, got %d", len(handler.updaters))
+	}
+}
+
+func TestUpdateHandlerSpawnDuringUpdate(t *testing.T) {
+	handler := &UpdateHandler{}
+	spawned := &MockUpdater{}
+	spawner := &MockUpdater{}
+	spawner.onUpdate = func() {
+		if spawner.updated == 1 {
+			handler.Add(spawned)
+		}
+	}
+
+	handler.Add(spawner)
+
+	handler.HandleUpdate()
+
+	if spawned.updated != 0 {
+		t.Errorf("updater added during the update should not be updated in the same frame")
+	}
+	if len(handler.updaters) != 2 {
+		t.Errorf("expected 2 updaters after the update, got %d", len(handler.updaters))
+	}
+
+	handler.HandleUpdate()
+
+	if spawned.updated != 1 {
+		t.Errorf("updater added during the previous update should be updated in the next frame")
+	}
+}
+
+func TestUpdateHandlerClearDuringUpdate(t *testing.T) {
+	handler := &UpdateHandler{}
+	obj1 := &MockUpdater{}
+	obj2 := &MockUpdater{}
+	obj1.onUpdate = func() { handler.Clear() }
+
+	handler.Add(obj1)
+	handler.Add(obj2)
+
+	handler.HandleUpdate()
+
+	if obj2.updated != 0 {
+		t.Errorf("updater should not be updated after Clear")
+	}
+	if len(handler.updaters) != 0 {
+		t.Errorf("expected 0 updaters, got %d", len(handler.updaters))
+	}
+}